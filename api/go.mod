@@ -14,6 +14,7 @@ require (
 	github.com/swaggo/swag v1.16.3
 	go.uber.org/zap v1.26.0
 	golang.org/x/oauth2 v0.15.0
+	golang.org/x/sync v0.8.0
 	gorm.io/driver/postgres v1.5.4
 	gorm.io/gorm v1.25.5
 )
@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fiber-app/internal/middleware"
 	"fiber-app/internal/services"
 	"fiber-app/pkg/cache"
+	"fiber-app/pkg/i18n"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +17,12 @@ import (
 
 var authService *services.AuthService
 
+const (
+	// Eşzamanlı callback'in diğerinin bitmesini bekleyeceği süre
+	callbackWaitTimeout  = 5 * time.Second
+	callbackPollInterval = 100 * time.Millisecond
+)
+
 // SetAuthService - Auth service'i set eder
 func SetAuthService(as *services.AuthService) {
 	authService = as
@@ -23,7 +34,10 @@ func SetAuthService(as *services.AuthService) {
 // @Tags Auth
 // @Accept json
 // @Produce json
+// @Param redirect_uri query string false "İzinli callback URL'i (native uygulamalar için)"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /auth/login [get]
 func Login(c *fiber.Ctx) error {
 	traceID := getTraceID(c)
@@ -33,36 +47,40 @@ func Login(c *fiber.Ctx) error {
 	)
 
 	if authService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Auth service yapılandırılmamış",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthNotConfigured)
+	}
+
+	// Limit PKCE verifier yazılmadan önce kontrol edilir; reddedilen istek Redis'te iz bırakmaz
+	if err := reservePendingState(c); err != nil {
+		return storeAuthStateError(c, err)
 	}
 
 	// OAuth2 authorization URL oluştur
-	authURL, state, err := authService.GenerateAuthURL()
+	authURL, state, err := authService.GenerateAuthURL(c.Query("redirect_uri"))
 	if err != nil {
+		releasePendingState(c.IP())
+		if errors.Is(err, services.ErrRedirectURINotAllowed) {
+			zapLogger.Warn("İzinsiz redirect_uri",
+				zap.String("trace_id", traceID),
+				zap.String("redirect_uri", c.Query("redirect_uri")),
+			)
+			return errorResponse(c, fiber.StatusBadRequest, i18n.CodeRedirectURINotAllowed)
+		}
+
 		zapLogger.Error("Auth URL oluşturulamadı",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Auth URL oluşturulamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthURLFailed)
 	}
 
 	// State'i cache'e kaydet (CSRF koruması için)
-	if err := cache.Set("auth_state:"+state, traceID, 10*time.Minute); err != nil {
-		zapLogger.Warn("State cache'e kaydedilemedi",
-			zap.String("trace_id", traceID),
-			zap.Error(err),
-		)
+	if err := storeAuthState(c, state); err != nil {
+		return storeAuthStateError(c, err)
 	}
 
 	zapLogger.Info("Auth URL oluşturuldu",
 		zap.String("trace_id", traceID),
-		zap.String("state", state),
 	)
 
 	return c.JSON(fiber.Map{
@@ -79,35 +97,42 @@ func Login(c *fiber.Ctx) error {
 // @Tags Auth
 // @Accept json
 // @Produce json
+// @Param redirect_uri query string false "İzinli callback URL'i (native uygulamalar için)"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /auth/login/redirect [get]
 func LoginRedirect(c *fiber.Ctx) error {
 	traceID := getTraceID(c)
 
 	if authService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Auth service yapılandırılmamış",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthNotConfigured)
+	}
+
+	if err := reservePendingState(c); err != nil {
+		return storeAuthStateError(c, err)
 	}
 
-	authURL, state, err := authService.GenerateAuthURL()
+	authURL, state, err := authService.GenerateAuthURL(c.Query("redirect_uri"))
 	if err != nil {
+		releasePendingState(c.IP())
+		if errors.Is(err, services.ErrRedirectURINotAllowed) {
+			zapLogger.Warn("İzinsiz redirect_uri",
+				zap.String("trace_id", traceID),
+				zap.String("redirect_uri", c.Query("redirect_uri")),
+			)
+			return errorResponse(c, fiber.StatusBadRequest, i18n.CodeRedirectURINotAllowed)
+		}
+
 		zapLogger.Error("Auth URL oluşturulamadı",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Auth URL oluşturulamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthURLFailed)
 	}
 
 	// State'i cache'e kaydet
-	if err := cache.Set("auth_state:"+state, traceID, 10*time.Minute); err != nil {
-		zapLogger.Warn("State cache'e kaydedilemedi",
-			zap.String("trace_id", traceID),
-			zap.Error(err),
-		)
+	if err := storeAuthState(c, state); err != nil {
+		return storeAuthStateError(c, err)
 	}
 
 	return c.Redirect(authURL)
@@ -123,6 +148,8 @@ func LoginRedirect(c *fiber.Ctx) error {
 // @Param state query string true "State parameter"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /auth/callback [get]
 func Callback(c *fiber.Ctx) error {
@@ -133,61 +160,114 @@ func Callback(c *fiber.Ctx) error {
 
 	zapLogger.Info("Auth callback çağrıldı",
 		zap.String("trace_id", traceID),
-		zap.String("state", state),
 		zap.Bool("has_code", code != ""),
 	)
 
-	if code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Authorization code gerekli",
-			"trace_id": traceID,
-		})
+	if state == "" {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeStateRequired)
 	}
 
-	if state == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "State parameter gerekli",
-			"trace_id": traceID,
-		})
+	// Aynı login az önce tamamlandıysa (çift tıklama/retry) ikinci bir session ve token üretilmez
+	if code != "" && callbackCompleted(state, code) {
+		return errorResponse(c, fiber.StatusConflict, i18n.CodeLoginAlreadyCompleted)
 	}
 
-	// State'i validate et (CSRF koruması)
-	var cachedTraceID string
-	if err := cache.Get("auth_state:"+state, &cachedTraceID); err != nil {
-		zapLogger.Warn("State validation başarısız",
+	// Aynı state için sadece bir callback işlenir
+	locked, err := cache.SetNX(services.AuthCallbackLockPrefix+state, traceID, services.AuthCallbackLockTTL)
+	if err != nil {
+		zapLogger.Error("Callback lock alınamadı",
 			zap.String("trace_id", traceID),
-			zap.String("state", state),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz state parameter",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeCallbackFailed)
 	}
+	if !locked {
+		return waitForCallback(c, state, code)
+	}
+
+	// State'i validate et (CSRF koruması); sayacı düşürebilmek için IP, state silinmeden önce okunur
+	var pending pendingAuthState
+	stateErr := cache.Get(services.AuthStatePrefix+state, &pending)
+
+	// State, PKCE verifier ve lock callback sonucu ne olursa olsun (başarı/hata) silinir, IP'nin bekleyen state slotu bırakılır
+	defer func() {
+		if err := cache.Delete(services.AuthStatePrefix+state, services.PKCEPrefix+state, services.AuthCallbackLockPrefix+state); err != nil {
+			zapLogger.Warn("State cache'den silinemedi",
+				zap.String("trace_id", traceID),
+				zap.Error(err),
+			)
+		}
+		releasePendingState(pending.IP)
+	}()
 
-	// State'i cache'den sil
-	cache.Delete("auth_state:" + state)
+	if code == "" {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeCodeRequired)
+	}
+
+	if stateErr != nil {
+		zapLogger.Warn("State validation başarısız",
+			zap.String("trace_id", traceID),
+			zap.Error(stateErr),
+		)
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidState)
+	}
 
 	if authService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Auth service yapılandırılmamış",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthNotConfigured)
 	}
 
 	ctx := context.Background()
 
 	// Authorization code'u token ile değiştir
-	token, err := authService.ExchangeCodeForToken(ctx, code)
+	token, err := authService.ExchangeCodeForToken(ctx, state, code)
 	if err != nil {
+		if errors.Is(err, services.ErrNonceMismatch) {
+			zapLogger.Warn("ID token nonce doğrulanamadı",
+				zap.String("trace_id", traceID),
+				zap.Error(err),
+			)
+			return errorResponse(c, fiber.StatusUnauthorized, i18n.CodeInvalidNonce)
+		}
+
 		zapLogger.Error("Token exchange başarısız",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Token exchange başarısız",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeTokenExchangeFailed)
+	}
+
+	// Step-up (acr/amr) gereksinimlerini kontrol et
+	if err := authService.ValidateAuthenticationContext(token); err != nil {
+		var stepUp *services.StepUpRequiredError
+		if errors.As(err, &stepUp) {
+			zapLogger.Warn("Step-up authentication gerekli",
+				zap.String("trace_id", traceID),
+				zap.Error(err),
+			)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":            i18n.T(c, i18n.CodeStepUpRequired),
+				"code":             i18n.CodeStepUpRequired,
+				"step_up_required": true,
+				"required_acr":     stepUp.RequiredACR,
+				"required_amr":     stepUp.RequiredAMR,
+				"trace_id":         traceID,
+			})
+		}
+
+		zapLogger.Error("Authentication context doğrulanamadı",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthContextFailed)
+	}
+
+	// ID token bu client için mi verilmiş (azp)
+	if err := authService.ValidateAuthorizedParty(token); err != nil {
+		zapLogger.Warn("ID token authorized party doğrulanamadı",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusUnauthorized, i18n.CodeUnauthorizedParty)
 	}
 
 	// Kullanıcı bilgilerini al
@@ -197,23 +277,20 @@ func Callback(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "User info alınamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeUserInfoFailed)
 	}
 
+	// İstemci sertifikası varsa token ve session bu sertifikaya bağlanır
+	certThumbprint := middleware.ClientCertFingerprint(c, authService.ClientCertHeader())
+
 	// JWT token oluştur
-	jwtToken, err := authService.CreateJWTToken(userInfo)
+	jwtToken, err := authService.CreateJWTToken(userInfo, certThumbprint)
 	if err != nil {
 		zapLogger.Error("JWT token oluşturulamadı",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "JWT token oluşturulamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeTokenCreationFailed)
 	}
 
 	// User session'ını cache'e kaydet
@@ -225,8 +302,36 @@ func Callback(c *fiber.Ctx) error {
 		"roles":      userInfo.Roles,
 		"login_time": time.Now(),
 	}
+	if certThumbprint != "" {
+		sessionData["client_cert_fingerprint"] = certThumbprint
+	}
+	// Süresi dolmuş token fallback'i sadece bu session için verilmiş token'ı kabul eder
+	sessionData["token_hash"] = services.TokenHash(jwtToken)
+	// Logout'ta Zitadel oturumunu kapatırken id_token_hint olarak kullanılır
+	if idToken, ok := token.Extra("id_token").(string); ok {
+		sessionData["id_token"] = idToken
+	}
+	// Logout'ta Zitadel'de iptal edilmek üzere sunucu tarafı key ile şifrelenerek saklanır
+	for field, value := range map[string]string{
+		"idp_access_token":  token.AccessToken,
+		"idp_refresh_token": token.RefreshToken,
+	} {
+		if value == "" {
+			continue
+		}
+		sealed, err := authService.SealSessionSecret(userInfo.Sub, value)
+		if err != nil {
+			zapLogger.Warn("IdP token şifrelenemedi, logout'ta iptal edilemeyecek",
+				zap.String("trace_id", traceID),
+				zap.String("field", field),
+				zap.Error(err),
+			)
+			continue
+		}
+		sessionData[field] = sealed
+	}
 
-	if err := cache.Set(sessionKey, sessionData, 24*time.Hour); err != nil {
+	if err := cache.Set(sessionKey, sessionData, authService.SessionTTL()); err != nil {
 		zapLogger.Warn("Session cache'e kaydedilemedi",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
@@ -240,18 +345,145 @@ func Callback(c *fiber.Ctx) error {
 		zap.Strings("roles", userInfo.Roles),
 	)
 
+	// Eşzamanlı/tekrarlanan callback'ler sadece tamamlandı bilgisini görür; token cache'lenmez
+	if err := cache.Set(services.AuthResultPrefix+callbackKey(state, code), true, services.AuthResultTTL); err != nil {
+		zapLogger.Warn("Login tamamlandı işareti cache'e kaydedilemedi",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+	}
+
 	return c.JSON(fiber.Map{
 		"message":    "Giriş başarılı",
 		"token":      jwtToken,
 		"user_info":  userInfo,
-		"expires_in": 24 * 60 * 60, // 24 saat (saniye)
+		"expires_in": int(authService.TokenTTL().Seconds()),
 		"trace_id":   traceID,
 	})
 }
 
+// pendingAuthState - Login başlatıldığında state ile saklanan bilgiler
+type pendingAuthState struct {
+	TraceID string `json:"trace_id"`
+	IP      string `json:"ip"`
+}
+
+// errTooManyPendingStates - IP'nin bekleyen login state limiti dolu
+var errTooManyPendingStates = errors.New("too many pending auth states for ip")
+
+// reservePendingState - IP başına bekleyen state limitini uygular ve slot ayırır.
+// Login'de GenerateAuthURL'den önce çağrılır; slotu storeAuthState ya da hata yolu bırakır.
+func reservePendingState(c *fiber.Ctx) error {
+	limit := authService.MaxPendingStatesPerIP()
+	if limit <= 0 {
+		return nil
+	}
+
+	ip := c.IP()
+	count, err := cache.Incr(services.AuthStateIPPrefix+ip, services.AuthStateTTL)
+	if err != nil {
+		// Redis'e ulaşılamıyorsa state de kaydedilemez, limit yerine callback'teki state kontrolü devreye girer
+		zapLogger.Warn("Pending state sayacı artırılamadı",
+			zap.String("trace_id", getTraceID(c)),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if count > int64(limit) {
+		releasePendingState(ip)
+		zapLogger.Warn("IP için bekleyen login state limiti aşıldı",
+			zap.String("trace_id", getTraceID(c)),
+			zap.String("ip", ip),
+			zap.Int("limit", limit),
+		)
+		return errTooManyPendingStates
+	}
+
+	return nil
+}
+
+// storeAuthState - State'i cache'e kaydeder; başarısızlıkta ayrılan slotu ve PKCE verifier'ı geri alır
+func storeAuthState(c *fiber.Ctx, state string) error {
+	traceID := getTraceID(c)
+	ip := c.IP()
+
+	if err := cache.Set(services.AuthStatePrefix+state, pendingAuthState{TraceID: traceID, IP: ip}, services.AuthStateTTL); err != nil {
+		// Kaydedilmeyen state callback'te hiç bulunamaz; sayaç ve verifier burada geri alınmazsa TTL dolana kadar kalır
+		releasePendingState(ip)
+		if delErr := cache.Delete(services.PKCEPrefix + state); delErr != nil {
+			zapLogger.Warn("PKCE verifier silinemedi",
+				zap.String("trace_id", traceID),
+				zap.Error(delErr),
+			)
+		}
+		zapLogger.Error("State cache'e kaydedilemedi",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// storeAuthStateError - reservePendingState/storeAuthState hatasını HTTP yanıtına çevirir
+func storeAuthStateError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errTooManyPendingStates) {
+		return errorResponse(c, fiber.StatusTooManyRequests, i18n.CodeTooManyPendingLogins)
+	}
+	return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthURLFailed)
+}
+
+// releasePendingState - Tamamlanan/reddedilen login için IP'nin bekleyen state sayacını azaltır
+func releasePendingState(ip string) {
+	if ip == "" || authService == nil || authService.MaxPendingStatesPerIP() <= 0 {
+		return
+	}
+
+	if err := cache.Decr(services.AuthStateIPPrefix + ip); err != nil {
+		zapLogger.Warn("Pending state sayacı azaltılamadı",
+			zap.String("ip", ip),
+			zap.Error(err),
+		)
+	}
+}
+
+// callbackKey - Tamamlandı işaretinin key'i; state herkese açık olduğu için code ile birlikte hash'lenir
+func callbackKey(state, code string) string {
+	sum := sha256.Sum256([]byte(state + "\x00" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// callbackCompleted - Bu state ve code ile yapılan login kısa süre önce tamamlandı mı
+func callbackCompleted(state, code string) bool {
+	return cache.Exists(services.AuthResultPrefix + callbackKey(state, code))
+}
+
+// waitForCallback - Aynı state'i işleyen callback'in lock'u bırakmasını bekler
+// Sonuç hiçbir zaman kopyalanmaz; bekleyen istek ilk callback tamamlandıysa da 409 alır
+func waitForCallback(c *fiber.Ctx, state, code string) error {
+	traceID := getTraceID(c)
+
+	zapLogger.Info("Aynı state için callback zaten işleniyor, bitmesi bekleniyor",
+		zap.String("trace_id", traceID),
+	)
+
+	deadline := time.Now().Add(callbackWaitTimeout)
+	for time.Now().Before(deadline) && cache.Exists(services.AuthCallbackLockPrefix+state) {
+		time.Sleep(callbackPollInterval)
+	}
+
+	if code != "" && callbackCompleted(state, code) {
+		return errorResponse(c, fiber.StatusConflict, i18n.CodeLoginAlreadyCompleted)
+	}
+
+	// Lock hâlâ tutuluyor (zaman aşımı) veya ilk callback başarısız oldu
+	return errorResponse(c, fiber.StatusConflict, i18n.CodeCallbackInProgress)
+}
+
 // Logout - Çıkış yap
 // @Summary Logout
-// @Description Kullanıcı oturumunu sonlandır
+// @Description Kullanıcı oturumunu sonlandır ve Zitadel token'larını iptal et; RP logout açıksa Zitadel oturumunu kapatmak için end_session_url döner
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -265,10 +497,7 @@ func Logout(c *fiber.Ctx) error {
 	// User ID'yi context'ten al
 	userID, ok := c.Locals("user_id").(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":    "Geçersiz oturum",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusUnauthorized, i18n.CodeInvalidSession)
 	}
 
 	zapLogger.Info("Logout endpoint çağrıldı",
@@ -276,8 +505,26 @@ func Logout(c *fiber.Ctx) error {
 		zap.String("user_id", userID),
 	)
 
-	// Session'ı cache'den sil
+	// Zitadel logout için id_token ve iptal edilecek token'lar session silinmeden önce okunur
 	sessionKey := "session:" + userID
+	var sessionData struct {
+		IDToken      string `json:"id_token"`
+		AccessToken  string `json:"idp_access_token"`
+		RefreshToken string `json:"idp_refresh_token"`
+	}
+	if err := cache.Get(sessionKey, &sessionData); err != nil {
+		zapLogger.Debug("Logout için session bulunamadı",
+			zap.String("trace_id", traceID),
+			zap.String("user_id", userID),
+		)
+	}
+
+	// Zitadel token'ları iptal edilir; başarısız olsa da lokal logout tamamlanır
+	if authService != nil {
+		revokeIdPTokens(c, userID, sessionData.RefreshToken, sessionData.AccessToken)
+	}
+
+	// Session'ı cache'den sil
 	if err := cache.Delete(sessionKey); err != nil {
 		zapLogger.Warn("Session cache'den silinemedi",
 			zap.String("trace_id", traceID),
@@ -291,10 +538,52 @@ func Logout(c *fiber.Ctx) error {
 		zap.String("user_id", userID),
 	)
 
-	return c.JSON(fiber.Map{
+	result := fiber.Map{
 		"message":  "Çıkış başarılı",
 		"trace_id": traceID,
-	})
+	}
+
+	// Frontend Zitadel oturumunu da kapatmak için bu URL'e yönlendirir
+	if authService != nil {
+		if endSessionURL := authService.BuildEndSessionURL(sessionData.IDToken, authService.PostLogoutRedirectURL()); endSessionURL != "" {
+			result["end_session_url"] = endSessionURL
+		}
+	}
+
+	return c.JSON(result)
+}
+
+// revokeIdPTokens - Session'daki (şifreli) Zitadel refresh ve access token'larını çözüp iptal eder
+// Refresh token önce iptal edilir; access token iptali tek başına yeni token alınmasını engellemez
+func revokeIdPTokens(c *fiber.Ctx, userID, sealedRefreshToken, sealedAccessToken string) {
+	tokens := []struct{ sealed, hint string }{
+		{sealedRefreshToken, "refresh_token"},
+		{sealedAccessToken, "access_token"},
+	}
+
+	for _, token := range tokens {
+		if token.sealed == "" {
+			continue
+		}
+		value, err := authService.OpenSessionSecret(userID, token.sealed)
+		if err != nil {
+			zapLogger.Warn("Session'daki Zitadel token'ı çözülemedi",
+				zap.String("trace_id", getTraceID(c)),
+				zap.String("user_id", userID),
+				zap.String("token_type_hint", token.hint),
+				zap.Error(err),
+			)
+			continue
+		}
+		if err := authService.RevokeToken(c.UserContext(), value, token.hint); err != nil {
+			zapLogger.Warn("Zitadel token iptal edilemedi",
+				zap.String("trace_id", getTraceID(c)),
+				zap.String("user_id", userID),
+				zap.String("token_type_hint", token.hint),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // Profile - Kullanıcı profili
@@ -332,6 +621,11 @@ func Profile(c *fiber.Ctx) error {
 		)
 	}
 
+	// Token'lar ve token_hash sadece sunucu tarafında kullanılır, client'a dönülmez
+	for _, key := range []string{"id_token", "token_hash", "idp_access_token", "idp_refresh_token"} {
+		delete(sessionData, key)
+	}
+
 	profile := fiber.Map{
 		"user_id":  userID,
 		"name":     userName,
@@ -0,0 +1,654 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fiber-app/internal/services"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// mockIdP - Token ve userinfo endpoint'lerini taklit eden Zitadel
+type mockIdP struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	nonce string
+	// ID token'a eklenecek ek claim'ler (acr, amr vb.)
+	idClaims jwt.MapClaims
+	// Token endpoint'ine gelen istek bu kanal kapanana kadar bekletilir (nil ise beklemez)
+	hold    chan struct{}
+	entered chan struct{}
+
+	exchanges     atomic.Int32
+	userInfoCalls atomic.Int32
+	revoked       []string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	idp := &mockIdP{entered: make(chan struct{}, 10)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		idp.exchanges.Add(1)
+		idp.entered <- struct{}{}
+
+		idp.mu.Lock()
+		hold, nonce := idp.hold, idp.nonce
+		claims := jwt.MapClaims{"sub": "user-1", "nonce": nonce}
+		for k, v := range idp.idClaims {
+			claims[k] = v
+		}
+		idp.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+
+		idToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("idp-test-key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "idp-access-token",
+			"refresh_token": "idp-refresh-token",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"id_token":      idToken,
+		})
+	})
+	mux.HandleFunc("/oidc/v1/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		idp.userInfoCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":   "user-1",
+			"name":  "Test User",
+			"email": "test@example.com",
+		})
+	})
+	mux.HandleFunc("/oauth/v2/revoke", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		idp.revoked = append(idp.revoked, r.PostForm.Get("token_type_hint")+":"+r.PostForm.Get("token"))
+		idp.mu.Unlock()
+	})
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// setNonce - IdP'nin ID token'a koyacağı nonce
+func (idp *mockIdP) setNonce(nonce string) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.nonce = nonce
+}
+
+// setIDTokenClaims - IdP'nin ID token'a ekleyeceği claim'ler
+func (idp *mockIdP) setIDTokenClaims(claims jwt.MapClaims) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.idClaims = claims
+}
+
+// holdExchanges - Token endpoint'ini serbest bırakılana kadar bekletir
+func (idp *mockIdP) holdExchanges() (release func()) {
+	hold := make(chan struct{})
+	idp.mu.Lock()
+	idp.hold = hold
+	idp.mu.Unlock()
+	return func() { close(hold) }
+}
+
+// revokedTokens - İptal edilen token'lar, "hint:token" biçiminde ve geliş sırasıyla
+func (idp *mockIdP) revokedTokens() []string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return append([]string(nil), idp.revoked...)
+}
+
+// setupAuth - Mock IdP'ye bağlı AuthService'i handler'lara set eder
+func setupAuth(t *testing.T, idp *mockIdP, cfg config.ZitadelConfig, jwtCfg config.JWTConfig) *services.AuthService {
+	t.Helper()
+
+	cfg.Domain = idp.URL
+	cfg.ClientID = "test-client"
+	cfg.ClientSecret = "test-secret"
+	cfg.RedirectURL = "http://localhost:3003/auth/callback"
+	if cfg.PKCETTL == 0 {
+		cfg.PKCETTL = time.Minute
+	}
+	if jwtCfg.Issuer == "" {
+		jwtCfg.Issuer = "fiber-app-test"
+	}
+	if jwtCfg.TokenTTL == 0 {
+		jwtCfg.TokenTTL = time.Hour
+	}
+
+	as, err := services.NewAuthService(&cfg, &jwtCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+
+	SetAuthService(as)
+	t.Cleanup(func() { SetAuthService(nil) })
+	return as
+}
+
+func authApp() *fiber.App {
+	app := traceApp()
+	app.Get("/auth/login", Login)
+	app.Get("/auth/callback", Callback)
+	return app
+}
+
+// startLogin - Login başlatır, state'i döner ve nonce'u IdP'ye bildirir
+func startLogin(t *testing.T, app *fiber.App, idp *mockIdP) string {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/login", nil))
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("login status = %d", resp.StatusCode)
+	}
+
+	body := decodeJSON(t, resp)
+	authURL, err := url.Parse(body["auth_url"].(string))
+	if err != nil {
+		t.Fatalf("parse auth_url: %v", err)
+	}
+	idp.setNonce(authURL.Query().Get("nonce"))
+
+	return body["state"].(string)
+}
+
+// callback - Callback'i çağırır (timeout'suz, eşzamanlı bekleme süresince bloklayabilir)
+func callback(t *testing.T, app *fiber.App, state, code string) (int, fiber.Map) {
+	t.Helper()
+
+	query := url.Values{"state": {state}}
+	if code != "" {
+		query.Set("code", code)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/callback?"+query.Encode(), nil), -1)
+	if err != nil {
+		t.Errorf("callback: %v", err)
+		return 0, nil
+	}
+	return resp.StatusCode, decodeJSON(t, resp)
+}
+
+func TestConcurrentCallbacksCreateSingleSession(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+	app := authApp()
+
+	state := startLogin(t, app, idp)
+	release := idp.holdExchanges()
+
+	type result struct {
+		status int
+		body   fiber.Map
+	}
+	results := make(chan result, 2)
+	run := func() {
+		status, body := callback(t, app, state, "auth-code")
+		results <- result{status, body}
+	}
+
+	// İlki token exchange'de beklerken ikincisi aynı state ile gelir
+	go run()
+	<-idp.entered
+	go run()
+	time.Sleep(3 * callbackPollInterval)
+	release()
+
+	var ok, conflict result
+	for i := 0; i < 2; i++ {
+		r := <-results
+		switch r.status {
+		case fiber.StatusOK:
+			ok = r
+		case fiber.StatusConflict:
+			conflict = r
+		default:
+			t.Fatalf("unexpected status %d: %v", r.status, r.body)
+		}
+	}
+
+	if ok.body == nil || ok.body["token"] == nil {
+		t.Fatal("first callback should return a token")
+	}
+	if conflict.body == nil || conflict.body["code"] != "login_already_completed" {
+		t.Fatalf("duplicate callback = %v, want login_already_completed", conflict.body)
+	}
+	if _, leaked := conflict.body["token"]; leaked {
+		t.Error("duplicate callback must not receive the token")
+	}
+
+	if n := idp.exchanges.Load(); n != 1 {
+		t.Errorf("token exchanges = %d, want 1", n)
+	}
+	if n := idp.userInfoCalls.Load(); n != 1 {
+		t.Errorf("userinfo calls = %d, want 1", n)
+	}
+	if !redis.Exists("session:user-1") {
+		t.Error("session was not created")
+	}
+}
+
+func TestCompletedCallbackDoesNotRevealToken(t *testing.T) {
+	cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+	app := authApp()
+
+	state := startLogin(t, app, idp)
+	if status, body := callback(t, app, state, "auth-code"); status != fiber.StatusOK {
+		t.Fatalf("first callback status = %d: %v", status, body)
+	}
+
+	// State login URL'inde ve loglarda görülebilir; code olmadan ya da yanlış code ile token alınamaz
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+	}{
+		{"state only", "", fiber.StatusBadRequest},
+		{"wrong code", "other-code", fiber.StatusBadRequest},
+		{"same code replayed", "auth-code", fiber.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := callback(t, app, state, tt.code)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if _, leaked := body["token"]; leaked {
+				t.Error("response must not contain a token")
+			}
+		})
+	}
+
+	if n := idp.exchanges.Load(); n != 1 {
+		t.Errorf("token exchanges = %d, want 1", n)
+	}
+}
+
+func TestAuthFlowDoesNotLogState(t *testing.T) {
+	cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+
+	core, logs := observer.New(zap.DebugLevel)
+	SetLogger(zap.New(core))
+	t.Cleanup(func() { SetLogger(zap.NewNop()) })
+
+	app := authApp()
+	state := startLogin(t, app, idp)
+	callback(t, app, state, "auth-code")
+	callback(t, app, state, "wrong-code")
+
+	for _, entry := range logs.All() {
+		for _, field := range entry.Context {
+			if field.Key == "state" || field.String == state {
+				t.Errorf("log %q contains the state", entry.Message)
+			}
+		}
+	}
+}
+
+func TestCallbackBindsSessionToIssuedToken(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{
+		ExpiredTokenSessionFallback: true,
+		ExpiredTokenGrace:           15 * time.Minute,
+	})
+	app := authApp()
+
+	state := startLogin(t, app, idp)
+	status, body := callback(t, app, state, "auth-code")
+	if status != fiber.StatusOK {
+		t.Fatalf("callback status = %d: %v", status, body)
+	}
+
+	raw, ok := redis.Get("session:user-1")
+	if !ok {
+		t.Fatal("session was not created")
+	}
+	var session struct {
+		TokenHash string `json:"token_hash"`
+	}
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		t.Fatalf("decode session: %v", err)
+	}
+	if want := services.TokenHash(body["token"].(string)); session.TokenHash != want {
+		t.Errorf("session token_hash = %q, want %q", session.TokenHash, want)
+	}
+
+	// Fallback için session, token'dan grace süresi kadar uzun yaşamalı
+	if ttl := redis.TTL("session:user-1"); ttl <= time.Hour {
+		t.Errorf("session TTL = %v, want > token TTL", ttl)
+	}
+}
+
+func TestLogoutRevokesIdPTokensAndDeletesSession(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{RPLogout: true, PostLogoutRedirectURL: "http://localhost:3000/"}, config.JWTConfig{})
+	app := authApp()
+	app.Post("/auth/logout", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-1")
+		return c.Next()
+	}, Logout)
+
+	state := startLogin(t, app, idp)
+	if status, body := callback(t, app, state, "auth-code"); status != fiber.StatusOK {
+		t.Fatalf("callback status = %d: %v", status, body)
+	}
+
+	// IdP token'ları Redis'e şifreli yazılır; session okunabilse de token'lar açığa çıkmaz
+	raw, _ := redis.Get("session:user-1")
+	for _, token := range []string{"idp-access-token", "idp-refresh-token"} {
+		if strings.Contains(raw, token) {
+			t.Errorf("session stores %s in plaintext", token)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/auth/logout", nil))
+	if err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("logout status = %d", resp.StatusCode)
+	}
+	body := decodeJSON(t, resp)
+
+	want := []string{"refresh_token:idp-refresh-token", "access_token:idp-access-token"}
+	if got := idp.revokedTokens(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("revoked = %v, want %v", got, want)
+	}
+	if redis.Exists("session:user-1") {
+		t.Error("session was not deleted")
+	}
+
+	endSession, err := url.Parse(body["end_session_url"].(string))
+	if err != nil {
+		t.Fatalf("parse end_session_url: %v", err)
+	}
+	if endSession.Query().Get("id_token_hint") == "" {
+		t.Error("end_session_url should carry the id_token_hint stored at login")
+	}
+	if got := endSession.Query().Get("post_logout_redirect_uri"); got != "http://localhost:3000/" {
+		t.Errorf("post_logout_redirect_uri = %q", got)
+	}
+}
+
+func TestProfileHidesIdPTokens(t *testing.T) {
+	cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+	app := authApp()
+	app.Get("/auth/profile", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-1")
+		return c.Next()
+	}, Profile)
+
+	state := startLogin(t, app, idp)
+	if status, body := callback(t, app, state, "auth-code"); status != fiber.StatusOK {
+		t.Fatalf("callback status = %d: %v", status, body)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/profile", nil))
+	if err != nil {
+		t.Fatalf("profile: %v", err)
+	}
+	session, _ := decodeJSON(t, resp)["session"].(map[string]interface{})
+	if session == nil {
+		t.Fatal("profile should include the session")
+	}
+	for _, key := range []string{"id_token", "token_hash", "idp_access_token", "idp_refresh_token"} {
+		if _, ok := session[key]; ok {
+			t.Errorf("profile session exposes %s", key)
+		}
+	}
+}
+
+// pendingStates - Test istemcisinin IP'si için bekleyen state sayacı
+func pendingStates(t *testing.T, redis *cachetest.Server) int {
+	t.Helper()
+
+	keys := redis.Keys(services.AuthStateIPPrefix + "*")
+	if len(keys) == 0 {
+		return 0
+	}
+	value, _ := redis.Get(keys[0])
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		t.Fatalf("pending counter %q: %v", value, err)
+	}
+	return count
+}
+
+func loginStatus(t *testing.T, app *fiber.App) int {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/login", nil))
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestPendingStateLimitIsReleased(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{MaxPendingStatesPerIP: 2}, config.JWTConfig{})
+	app := authApp()
+
+	first := startLogin(t, app, idp)
+	second := startLogin(t, app, idp)
+	if status := loginStatus(t, app); status != fiber.StatusTooManyRequests {
+		t.Fatalf("login over the limit = %d, want 429", status)
+	}
+	if n := pendingStates(t, redis); n != 2 {
+		t.Fatalf("pending = %d after rejected login, want 2", n)
+	}
+	if keys := redis.Keys(services.PKCEPrefix + "*"); len(keys) != 2 {
+		t.Fatalf("pkce keys after rejected login = %v, want only the two accepted logins", keys)
+	}
+
+	// Code'suz callback erken döner ama slotu bırakmalı
+	if status, _ := callback(t, app, first, ""); status != fiber.StatusBadRequest {
+		t.Fatalf("callback without code = %d, want 400", status)
+	}
+	if n := pendingStates(t, redis); n != 1 {
+		t.Errorf("pending = %d after callback without code, want 1", n)
+	}
+
+	// Başarılı callback de slotu bırakır
+	if status, body := callback(t, app, second, "auth-code"); status != fiber.StatusOK {
+		t.Fatalf("callback = %d: %v", status, body)
+	}
+	if n := pendingStates(t, redis); n != 0 {
+		t.Errorf("pending = %d after successful callback, want 0", n)
+	}
+
+	// Bilinmeyen state sayacı değiştirmez
+	callback(t, app, "unknown-state", "auth-code")
+	if n := pendingStates(t, redis); n != 0 {
+		t.Errorf("pending = %d after unknown state, want 0", n)
+	}
+
+	if status := loginStatus(t, app); status != fiber.StatusOK {
+		t.Errorf("login after release = %d, want 200", status)
+	}
+}
+
+func TestPendingStateReleasedWhenStateCannotBeStored(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp := newMockIdP(t)
+	setupAuth(t, idp, config.ZitadelConfig{MaxPendingStatesPerIP: 1}, config.JWTConfig{})
+	app := authApp()
+
+	redis.FailCommand("SET", services.AuthStatePrefix)
+	if status := loginStatus(t, app); status != fiber.StatusInternalServerError {
+		t.Fatalf("login with failing state store = %d, want 500", status)
+	}
+	if n := pendingStates(t, redis); n != 0 {
+		t.Errorf("pending = %d after failed store, want 0", n)
+	}
+	if keys := redis.Keys(services.PKCEPrefix + "*"); len(keys) != 0 {
+		t.Errorf("pkce keys after failed store = %v, want none", keys)
+	}
+}
+
+func TestCallbackRequiresStepUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		idClaims jwt.MapClaims
+		wantOK   bool
+	}{
+		{"amr met", jwt.MapClaims{"acr": "urn:zitadel:mfa", "amr": []string{"pwd", "mfa"}}, true},
+		{"amr missing mfa", jwt.MapClaims{"acr": "urn:zitadel:mfa", "amr": []string{"pwd"}}, false},
+		{"acr mismatch", jwt.MapClaims{"acr": "urn:zitadel:pwd", "amr": []string{"mfa"}}, false},
+		{"no claims", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redis := cachetest.Start(t)
+			idp := newMockIdP(t)
+			setupAuth(t, idp, config.ZitadelConfig{
+				RequiredACR: "urn:zitadel:mfa",
+				RequiredAMR: []string{"mfa"},
+			}, config.JWTConfig{})
+			idp.setIDTokenClaims(tt.idClaims)
+			app := authApp()
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/auth/login", nil))
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			login := decodeJSON(t, resp)
+			authURL, _ := url.Parse(login["auth_url"].(string))
+			if got := authURL.Query().Get("acr_values"); got != "urn:zitadel:mfa" {
+				t.Errorf("acr_values = %q, want urn:zitadel:mfa", got)
+			}
+			idp.setNonce(authURL.Query().Get("nonce"))
+
+			status, body := callback(t, app, login["state"].(string), "auth-code")
+			if tt.wantOK {
+				if status != fiber.StatusOK {
+					t.Fatalf("callback status = %d: %v", status, body)
+				}
+				return
+			}
+
+			if status != fiber.StatusUnauthorized || body["step_up_required"] != true {
+				t.Fatalf("callback = %d %v, want 401 with step_up_required", status, body)
+			}
+			if body["required_acr"] != "urn:zitadel:mfa" || fmt.Sprint(body["required_amr"]) != "[mfa]" {
+				t.Errorf("step-up requirements = %v %v", body["required_acr"], body["required_amr"])
+			}
+			if redis.Exists("session:user-1") {
+				t.Error("session created although step-up is required")
+			}
+		})
+	}
+}
+
+func TestFailedCallbackRemovesStateAndPKCE(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		setNonce   bool
+		wantStatus int
+	}{
+		{"missing code", "", true, fiber.StatusBadRequest},
+		{"nonce mismatch", "auth-code", false, fiber.StatusUnauthorized},
+		{"success", "auth-code", true, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redis := cachetest.Start(t)
+			idp := newMockIdP(t)
+			setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+			app := authApp()
+
+			state := startLogin(t, app, idp)
+			if !tt.setNonce {
+				idp.setNonce("another-login")
+			}
+			for _, prefix := range []string{services.AuthStatePrefix, services.PKCEPrefix} {
+				if !redis.Exists(prefix + state) {
+					t.Fatalf("%s key was not created at login", prefix)
+				}
+			}
+
+			if status, body := callback(t, app, state, tt.code); status != tt.wantStatus {
+				t.Fatalf("callback status = %d, want %d: %v", status, tt.wantStatus, body)
+			}
+			for _, prefix := range []string{services.AuthStatePrefix, services.PKCEPrefix, services.AuthCallbackLockPrefix} {
+				if redis.Exists(prefix + state) {
+					t.Errorf("%s key left behind after callback", prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestCallbackChecksAuthorizedParty(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkAzp   bool
+		azp        interface{}
+		wantStatus int
+	}{
+		{"matching azp", true, "test-client", fiber.StatusOK},
+		{"other client", true, "other-client", fiber.StatusUnauthorized},
+		{"azp missing", true, nil, fiber.StatusOK},
+		{"check disabled", false, "other-client", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachetest.Start(t)
+			idp := newMockIdP(t)
+			setupAuth(t, idp, config.ZitadelConfig{CheckAzp: tt.checkAzp}, config.JWTConfig{})
+			if tt.azp != nil {
+				idp.setIDTokenClaims(jwt.MapClaims{"azp": tt.azp})
+			}
+			app := authApp()
+
+			status, body := callback(t, app, startLogin(t, app, idp), "auth-code")
+			if status != tt.wantStatus {
+				t.Fatalf("callback status = %d, want %d: %v", status, tt.wantStatus, body)
+			}
+			if status == fiber.StatusUnauthorized && body["code"] != "unauthorized_party" {
+				t.Errorf("code = %v, want unauthorized_party", body["code"])
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fiber-app/internal/services"
 	"fiber-app/pkg/cache"
+	"fiber-app/pkg/i18n"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -33,16 +34,13 @@ func GetCacheStats(c *fiber.Ctx) error {
 	)
 
 	// Cache service stats
-	stats, err := cacheService.GetCacheStats()
+	stats, err := requestCache(c).GetCacheStats()
 	if err != nil {
 		zapLogger.Error("Cache stats alınamadı",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Cache stats alınamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeCacheStatsFailed)
 	}
 
 	// Redis info
@@ -83,10 +81,7 @@ func FlushCache(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Cache flush başarısız",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeCacheFlushFailed)
 	}
 
 	zapLogger.Info("Cache başarıyla temizlendi",
@@ -122,16 +117,13 @@ func GetCacheKeys(c *fiber.Ctx) error {
 		zap.Int("limit", limit),
 	)
 
-	keys, err := cache.Keys(pattern)
+	keys, err := cache.Scan(pattern, cache.DefaultScanCount)
 	if err != nil {
 		zapLogger.Error("Cache keys alınamadı",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Cache keys alınamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeCacheKeysFailed)
 	}
 
 	// Limit uygula
@@ -163,10 +155,7 @@ func DeleteCacheKey(c *fiber.Ctx) error {
 
 	key := c.Params("key")
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Key parametresi gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeCacheKeyRequired)
 	}
 
 	zapLogger.Info("Cache key siliniyor",
@@ -181,10 +170,7 @@ func DeleteCacheKey(c *fiber.Ctx) error {
 			zap.String("key", key),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Cache key silinemedi",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeCacheDeleteFailed)
 	}
 
 	zapLogger.Info("Cache key başarıyla silindi",
@@ -198,3 +184,37 @@ func DeleteCacheKey(c *fiber.Ctx) error {
 		"trace_id": traceID,
 	})
 }
+
+// CleanupAuthStates - Bekleyen login state'lerini raporla ve orphan olanları temizle
+// @Summary Auth state temizliği
+// @Description Tamamlanmamış login state'lerini ve PKCE verifier'larını sayar; TTL'i olmayan state'leri ve state'i kalmamış verifier'ları siler
+// @Tags Cache
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/cache/auth-states/cleanup [post]
+func CleanupAuthStates(c *fiber.Ctx) error {
+	traceID := getTraceID(c)
+
+	zapLogger.Info("Auth state cleanup endpoint çağrıldı",
+		zap.String("trace_id", traceID),
+	)
+
+	result, err := requestCache(c).CleanupAuthStates()
+	if err != nil {
+		zapLogger.Error("Auth state cleanup başarısız",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeAuthStateCleanupFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"pending_auth_states": result.PendingStates,
+		"pending_pkce":        result.PendingPKCE,
+		"removed_auth_states": result.RemovedStates,
+		"removed_pkce":        result.RemovedPKCE,
+		"trace_id":            traceID,
+	})
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"fiber-app/internal/services"
+	"fiber-app/pkg/database"
+	"fiber-app/pkg/i18n"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 var zapLogger *zap.Logger
@@ -22,6 +26,56 @@ func getTraceID(c *fiber.Ctx) string {
 	return "unknown"
 }
 
+// errorResponse - İsteğin diline göre lokalize edilmiş hata mesajı ve kodu ile JSON hata döner
+func errorResponse(c *fiber.Ctx, status int, code i18n.Code) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error":    i18n.T(c, code),
+		"code":     code,
+		"trace_id": getTraceID(c),
+	})
+}
+
+// requestDB - Request context'i (trace_id) taşıyan DB session'ı döner
+func requestDB(c *fiber.Ctx) *gorm.DB {
+	return database.DB.WithContext(c.UserContext())
+}
+
+// requestCache - Loglarına request trace_id'sini ekleyen CacheService döner
+func requestCache(c *fiber.Ctx) *services.CacheService {
+	return cacheService.WithContext(c.UserContext())
+}
+
+// totalPages - Toplam sayfa sayısı, boş sonuçta da 1 sayfa döner
+func totalPages(total int64, limit int) int {
+	if total == 0 {
+		return 1
+	}
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// clampPage - Sayfa numarasını 1 ile total_pages arasına sınırlar
+func clampPage(page, limit int, total int64) int {
+	if page < 1 {
+		return 1
+	}
+	if pages := totalPages(total, limit); page > pages {
+		return pages
+	}
+	return page
+}
+
+// paginationMeta - Liste endpoint'leri için standart pagination bilgisi
+func paginationMeta(page, limit int, total int64) fiber.Map {
+	pages := totalPages(total, limit)
+	return fiber.Map{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": pages,
+		"has_more":    page < pages,
+	}
+}
+
 // Home - Ana sayfa
 // @Summary Ana sayfa
 // @Description Uygulama ana sayfası ve endpoint listesi
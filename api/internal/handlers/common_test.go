@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaginationMeta(t *testing.T) {
+	tests := []struct {
+		page, limit int
+		total       int64
+		wantPage    int
+		wantPages   int
+		wantHasMore bool
+	}{
+		// Boş sonuç: tek (boş) sayfa, istenen sayfa 1'e çekilir
+		{page: 1, limit: 10, total: 0, wantPage: 1, wantPages: 1},
+		{page: 5, limit: 10, total: 0, wantPage: 1, wantPages: 1},
+		// Tam bir sayfa
+		{page: 1, limit: 10, total: 10, wantPage: 1, wantPages: 1},
+		// Sayfa sınırları
+		{page: 1, limit: 10, total: 11, wantPage: 1, wantPages: 2, wantHasMore: true},
+		{page: 2, limit: 10, total: 11, wantPage: 2, wantPages: 2},
+		{page: 2, limit: 10, total: 30, wantPage: 2, wantPages: 3, wantHasMore: true},
+		{page: 9, limit: 10, total: 30, wantPage: 3, wantPages: 3},
+		{page: 0, limit: 10, total: 30, wantPage: 1, wantPages: 3, wantHasMore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("page %d limit %d total %d", tt.page, tt.limit, tt.total), func(t *testing.T) {
+			page := clampPage(tt.page, tt.limit, tt.total)
+			if page != tt.wantPage {
+				t.Errorf("clampPage = %d, want %d", page, tt.wantPage)
+			}
+
+			meta := paginationMeta(page, tt.limit, tt.total)
+			if meta["total_pages"] != tt.wantPages || meta["has_more"] != tt.wantHasMore {
+				t.Errorf("meta = %v, want total_pages %d and has_more %v", meta, tt.wantPages, tt.wantHasMore)
+			}
+		})
+	}
+}
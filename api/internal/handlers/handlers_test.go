@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	SetLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// traceApp - Gerçek uygulamadaki gibi her isteğe sabit bir trace_id ekleyen Fiber app
+func traceApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("trace_id", "test-trace")
+		return c.Next()
+	})
+	return app
+}
+
+// decodeJSON - Yanıt gövdesini fiber.Map olarak çözer
+func decodeJSON(t *testing.T, resp *http.Response) fiber.Map {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	var result fiber.Map
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("decode body %q: %v", body, err)
+	}
+	return result
+}
@@ -49,6 +49,10 @@ func GetMetrics(c *fiber.Ctx) error {
 		"trace_id":   traceID,
 	}
 
+	if authService != nil {
+		metrics["token_validation_failures"] = authService.TokenFailureStats()
+	}
+
 	return c.JSON(metrics)
 }
 
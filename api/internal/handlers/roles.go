@@ -3,7 +3,7 @@ package handlers
 import (
 	"errors"
 	"fiber-app/internal/models"
-	"fiber-app/pkg/database"
+	"fiber-app/pkg/i18n"
 	"strconv"
 	"strings"
 
@@ -38,64 +38,55 @@ func GetRoles(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	offset := (page - 1) * limit
-
 	zapLogger.Info("Roles listesi istendi",
 		zap.String("trace_id", traceID),
 		zap.Int("page", page),
 		zap.Int("limit", limit),
 	)
 
+	var roles []models.Role
+	var total int64
+
+	// Toplam sayı; cache sadece ilk sayfayı tuttuğu için cache'den dönülürken de gerekir
+	if err := requestDB(c).Model(&models.Role{}).Count(&total).Error; err != nil {
+		zapLogger.Error("Roles count hatası",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	// Sayfa numarasını mevcut sayfa sayısına göre sınırla
+	page = clampPage(page, limit, total)
+	offset := (page - 1) * limit
+
 	// Eğer sayfa 1 ve limit 10 ise cache'den kontrol et
 	if page == 1 && limit == 10 && cacheService != nil {
-		if cachedRoles, err := cacheService.GetAllRoles(); err == nil {
+		if cachedRoles, err := requestCache(c).GetAllRoles(); err == nil {
 			zapLogger.Info("Roles cache'den getirildi",
 				zap.String("trace_id", traceID),
 			)
 			return c.JSON(fiber.Map{
-				"roles": cachedRoles,
-				"pagination": fiber.Map{
-					"page":        page,
-					"limit":       limit,
-					"total":       int64(len(cachedRoles)),
-					"total_pages": 1,
-				},
-				"trace_id": traceID,
-				"cached":   true,
+				"roles":      cachedRoles,
+				"pagination": paginationMeta(page, limit, total),
+				"trace_id":   traceID,
+				"cached":     true,
 			})
 		}
 	}
 
-	var roles []models.Role
-	var total int64
-
-	// Toplam sayı
-	if err := database.DB.Model(&models.Role{}).Count(&total).Error; err != nil {
-		zapLogger.Error("Roles count hatası",
-			zap.String("trace_id", traceID),
-			zap.Error(err),
-		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
-	}
-
 	// Sayfalama ile veri çek
-	if err := database.DB.Offset(offset).Limit(limit).Order("created_at DESC").Find(&roles).Error; err != nil {
+	if err := requestDB(c).Offset(offset).Limit(limit).Order("created_at DESC").Find(&roles).Error; err != nil {
 		zapLogger.Error("Roles listesi hatası",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// İlk sayfa ise cache'e kaydet
 	if page == 1 && limit == 10 && cacheService != nil {
-		if err := cacheService.SetAllRoles(roles); err != nil {
+		if err := requestCache(c).SetAllRoles(roles); err != nil {
 			zapLogger.Warn("Roles cache'e kaydedilemedi",
 				zap.String("trace_id", traceID),
 				zap.Error(err),
@@ -104,14 +95,9 @@ func GetRoles(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"roles": roles,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-		"trace_id": traceID,
+		"roles":      roles,
+		"pagination": paginationMeta(page, limit, total),
+		"trace_id":   traceID,
 	})
 }
 
@@ -132,19 +118,13 @@ func GetRole(c *fiber.Ctx) error {
 
 	roleID := c.Params("id")
 	if roleID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Role ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeRoleIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(roleID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz Role ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidRoleID)
 	}
 
 	zapLogger.Info("Role detayı istendi",
@@ -153,12 +133,9 @@ func GetRole(c *fiber.Ctx) error {
 	)
 
 	var role models.Role
-	if err := database.DB.First(&role, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).First(&role, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "Role bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeRoleNotFound)
 		}
 
 		zapLogger.Error("Role getirme hatası",
@@ -166,10 +143,7 @@ func GetRole(c *fiber.Ctx) error {
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	return c.JSON(fiber.Map{
@@ -188,6 +162,7 @@ func GetRole(c *fiber.Ctx) error {
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/roles [post]
 func CreateRole(c *fiber.Ctx) error {
@@ -199,18 +174,16 @@ func CreateRole(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz JSON formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidJSON)
 	}
 
 	// Basit validasyon
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Name alanı gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeNameRequired)
+	}
+
+	if fe := roleFieldError(req.Name, req.Description); fe != nil {
+		return fieldErrorResponse(c, fe)
 	}
 
 	zapLogger.Info("Yeni role oluşturuluyor",
@@ -223,7 +196,7 @@ func CreateRole(c *fiber.Ctx) error {
 		Description: req.Description,
 	}
 
-	if err := database.DB.Create(&role).Error; err != nil {
+	if err := requestDB(c).Create(&role).Error; err != nil {
 		zapLogger.Error("Role oluşturma hatası",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
@@ -231,16 +204,10 @@ func CreateRole(c *fiber.Ctx) error {
 
 		// Name unique constraint hatası
 		if strings.Contains(err.Error(), "duplicate key") && strings.Contains(err.Error(), "name") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error":    "Bu role adı zaten kullanımda",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusConflict, i18n.CodeRoleNameTaken)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	zapLogger.Info("Role başarıyla oluşturuldu",
@@ -267,6 +234,7 @@ func CreateRole(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/roles/{id} [put]
 func UpdateRole(c *fiber.Ctx) error {
@@ -274,19 +242,13 @@ func UpdateRole(c *fiber.Ctx) error {
 
 	roleID := c.Params("id")
 	if roleID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Role ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeRoleIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(roleID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz Role ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidRoleID)
 	}
 
 	var req models.UpdateRoleRequest
@@ -295,10 +257,18 @@ func UpdateRole(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz JSON formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidJSON)
+	}
+
+	var name, description string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if fe := roleFieldError(name, description); fe != nil {
+		return fieldErrorResponse(c, fe)
 	}
 
 	zapLogger.Info("Role güncelleniyor",
@@ -308,12 +278,9 @@ func UpdateRole(c *fiber.Ctx) error {
 
 	// Önce role'ün var olup olmadığını kontrol et
 	var role models.Role
-	if err := database.DB.First(&role, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).First(&role, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "Role bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeRoleNotFound)
 		}
 
 		zapLogger.Error("Role bulma hatası",
@@ -321,10 +288,7 @@ func UpdateRole(c *fiber.Ctx) error {
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Güncelleme verilerini hazırla
@@ -338,14 +302,24 @@ func UpdateRole(c *fiber.Ctx) error {
 	}
 
 	if len(updates) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Güncellenecek alan bulunamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeNoFieldsToUpdate)
 	}
 
-	// Güncelle
-	if err := database.DB.Model(&role).Updates(updates).Error; err != nil {
+	// Değişiklik geçmişi güncellemeden önceki değerlerle hazırlanır
+	changedBy, _ := c.Locals("user_id").(string)
+	history := roleHistoryEntry(role, req, changedBy)
+
+	// Güncelleme ve geçmiş kaydı aynı transaction'da yapılır
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&role).Updates(updates).Error; err != nil {
+			return err
+		}
+		if history == nil {
+			return nil
+		}
+		return createRoleHistory(tx, history)
+	})
+	if err != nil {
 		zapLogger.Error("Role güncelleme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("role_id", roleID),
@@ -354,29 +328,31 @@ func UpdateRole(c *fiber.Ctx) error {
 
 		// Name unique constraint hatası
 		if strings.Contains(err.Error(), "duplicate key") && strings.Contains(err.Error(), "name") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error":    "Bu role adı zaten kullanımda",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusConflict, i18n.CodeRoleNameTaken)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	// Kullanıcılar rolü ID ile referans verir; sadece eski adı taşıyan cache'ler temizlenir
+	if history != nil && cacheService != nil {
+		if err := requestCache(c).InvalidateRoleCaches(id); err != nil {
+			zapLogger.Warn("Role cache'leri temizlenemedi",
+				zap.String("trace_id", traceID),
+				zap.String("role_id", roleID),
+				zap.Error(err),
+			)
+		}
 	}
 
 	// Güncellenmiş role'ü getir
-	if err := database.DB.First(&role, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).First(&role, "id = ?", id).Error; err != nil {
 		zapLogger.Error("Güncellenmiş role getirme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	zapLogger.Info("Role başarıyla güncellendi",
@@ -391,6 +367,126 @@ func UpdateRole(c *fiber.Ctx) error {
 	})
 }
 
+// GetRoleHistory - Rol değişiklik geçmişi
+// @Summary Rol geçmişi
+// @Description Rolün name/description değişikliklerini önceki ve sonraki değerleriyle, en yeniden eskiye listeler
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID (UUID)"
+// @Param page query int false "Sayfa numarası" default(1)
+// @Param limit query int false "Sayfa başına kayıt sayısı" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/roles/{id}/history [get]
+func GetRoleHistory(c *fiber.Ctx) error {
+	traceID := getTraceID(c)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidRoleID)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	zapLogger.Info("Role geçmişi istendi",
+		zap.String("trace_id", traceID),
+		zap.String("role_id", id.String()),
+	)
+
+	var total int64
+	if err := requestDB(c).Model(&models.RoleHistory{}).Where("role_id = ?", id).Count(&total).Error; err != nil {
+		zapLogger.Error("Role geçmişi count hatası",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	// Geçmişi olmayan rol için var olup olmadığını kontrol et (silinen rollerin geçmişi korunur)
+	if total == 0 {
+		var count int64
+		if err := requestDB(c).Model(&models.Role{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			zapLogger.Error("Role bulma hatası",
+				zap.String("trace_id", traceID),
+				zap.Error(err),
+			)
+			return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+		}
+		if count == 0 {
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeRoleNotFound)
+		}
+	}
+
+	page = clampPage(page, limit, total)
+	offset := (page - 1) * limit
+
+	var history []models.RoleHistory
+	if err := requestDB(c).Where("role_id = ?", id).Order("version DESC").Offset(offset).Limit(limit).Find(&history).Error; err != nil {
+		zapLogger.Error("Role geçmişi hatası",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	return c.JSON(fiber.Map{
+		"history":    history,
+		"pagination": paginationMeta(page, limit, total),
+		"trace_id":   traceID,
+	})
+}
+
+// roleHistoryEntry - Güncelleme isteğinin gerçekten değiştirdiği alanlar için geçmiş kaydı (değişiklik yoksa nil)
+func roleHistoryEntry(role models.Role, req models.UpdateRoleRequest, changedBy string) *models.RoleHistory {
+	entry := &models.RoleHistory{
+		RoleID:              role.ID,
+		PreviousName:        role.Name,
+		Name:                role.Name,
+		PreviousDescription: role.Description,
+		Description:         role.Description,
+		ChangedBy:           changedBy,
+	}
+
+	var changed []string
+	if req.Name != nil && *req.Name != role.Name {
+		entry.Name = *req.Name
+		changed = append(changed, "name")
+	}
+	if req.Description != nil && *req.Description != role.Description {
+		entry.Description = *req.Description
+		changed = append(changed, "description")
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	entry.ChangedFields = strings.Join(changed, ",")
+	return entry
+}
+
+// createRoleHistory - Sıradaki versiyon numarasıyla geçmiş kaydı ekler
+// Aynı transaction'daki UPDATE rol satırını kilitlediği için eşzamanlı güncellemeler aynı versiyonu alamaz
+func createRoleHistory(tx *gorm.DB, entry *models.RoleHistory) error {
+	var lastVersion int
+	if err := tx.Model(&models.RoleHistory{}).Where("role_id = ?", entry.RoleID).Select("COALESCE(MAX(version), 0)").Scan(&lastVersion).Error; err != nil {
+		return err
+	}
+
+	entry.Version = lastVersion + 1
+	return tx.Create(entry).Error
+}
+
 // DeleteRole - Rol sil
 // @Summary Rol sil
 // @Description Rolü sistemden sil (kullanımda değilse)
@@ -409,19 +505,13 @@ func DeleteRole(c *fiber.Ctx) error {
 
 	roleID := c.Params("id")
 	if roleID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Role ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeRoleIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(roleID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz Role ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidRoleID)
 	}
 
 	zapLogger.Info("Role siliniyor",
@@ -431,12 +521,9 @@ func DeleteRole(c *fiber.Ctx) error {
 
 	// Önce role'ün var olup olmadığını kontrol et
 	var role models.Role
-	if err := database.DB.First(&role, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).First(&role, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "Role bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeRoleNotFound)
 		}
 
 		zapLogger.Error("Role bulma hatası",
@@ -444,44 +531,33 @@ func DeleteRole(c *fiber.Ctx) error {
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Bu role'ü kullanan user var mı kontrol et
 	var userCount int64
-	if err := database.DB.Model(&models.User{}).Where("role_id = ?", id).Count(&userCount).Error; err != nil {
+	// Soft delete edilmiş kullanıcılar da foreign key ile role'e bağlı kalır
+	if err := requestDB(c).Unscoped().Model(&models.User{}).Where("role_id = ?", id).Count(&userCount).Error; err != nil {
 		zapLogger.Error("User count kontrol hatası",
 			zap.String("trace_id", traceID),
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	if userCount > 0 {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error":    "Bu role'ü kullanan kullanıcılar var, silinemez",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusConflict, i18n.CodeRoleInUse)
 	}
 
 	// Sil
-	if err := database.DB.Delete(&role).Error; err != nil {
+	if err := requestDB(c).Delete(&role).Error; err != nil {
 		zapLogger.Error("Role silme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("role_id", roleID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	zapLogger.Info("Role başarıyla silindi",
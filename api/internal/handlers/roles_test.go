@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"fiber-app/internal/models"
+	"fiber-app/internal/services"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/database/dbtest"
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var offsetPattern = regexp.MustCompile(`OFFSET (\d+)`)
+
+// rolesDB - n rolü count ve LIMIT/OFFSET sorgularına göre dönen sahte DB
+func rolesDB(t *testing.T, n int) *dbtest.DB {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	db.HandleQuery(`FROM "roles"`, func(query string, _ []driver.Value) dbtest.Result {
+		offset, limit := 0, n
+		if m := offsetPattern.FindStringSubmatch(query); m != nil {
+			offset, _ = strconv.Atoi(m[1])
+		}
+		if m := limitPattern.FindStringSubmatch(query); m != nil {
+			limit, _ = strconv.Atoi(m[1])
+		}
+
+		result := dbtest.Result{Columns: []string{"id", "name", "created_at"}}
+		for i := offset; i < n && i < offset+limit; i++ {
+			result.Rows = append(result.Rows, []driver.Value{uuid.NewString(), fmt.Sprintf("role-%d", i), time.Now()})
+		}
+		return result
+	})
+	db.Rows(`count(*)`, []string{"count"}, []driver.Value{int64(n)})
+	return db
+}
+
+// getRoles - GET /roles yanıtından rol sayısını ve pagination bilgisini döner
+func getRoles(t *testing.T, query string) (int, fiber.Map) {
+	t.Helper()
+
+	app := traceApp()
+	app.Get("/roles", GetRoles)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/roles?"+query, nil))
+	if err != nil {
+		t.Fatalf("GET /roles: %v", err)
+	}
+	body := decodeJSON(t, resp)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d: %v", resp.StatusCode, body)
+	}
+
+	roles, _ := body["roles"].([]interface{})
+	pagination, _ := body["pagination"].(map[string]interface{})
+	return len(roles), pagination
+}
+
+func TestGetRolesPagination(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		query       string
+		wantRoles   int
+		wantPage    float64
+		wantPages   float64
+		wantHasMore bool
+	}{
+		{"zero results", 0, "page=1&limit=5", 0, 1, 1, false},
+		{"zero results past the end", 0, "page=3&limit=5", 0, 1, 1, false},
+		{"exactly one page", 5, "page=1&limit=5", 5, 1, 1, false},
+		{"first of many", 12, "page=1&limit=5", 5, 1, 3, true},
+		{"last partial page", 12, "page=3&limit=5", 2, 3, 3, false},
+		{"page clamped to last", 12, "page=7&limit=5", 2, 3, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rolesDB(t, tt.total)
+
+			n, pagination := getRoles(t, tt.query)
+			if n != tt.wantRoles {
+				t.Errorf("roles = %d, want %d", n, tt.wantRoles)
+			}
+			if pagination["page"] != tt.wantPage || pagination["total_pages"] != tt.wantPages || pagination["has_more"] != tt.wantHasMore {
+				t.Errorf("pagination = %v, want page %v, total_pages %v, has_more %v", pagination, tt.wantPage, tt.wantPages, tt.wantHasMore)
+			}
+		})
+	}
+}
+
+func TestGetRolesCachedPageReportsTotal(t *testing.T) {
+	cachetest.Start(t)
+	rolesDB(t, 25)
+
+	previous := cacheService
+	SetCacheService(services.NewCacheService(zap.NewNop()))
+	t.Cleanup(func() { SetCacheService(previous) })
+
+	cached := make([]models.Role, 10)
+	for i := range cached {
+		cached[i] = models.Role{ID: uuid.New(), Name: fmt.Sprintf("role-%d", i)}
+	}
+	if err := cacheService.SetAllRoles(cached); err != nil {
+		t.Fatalf("SetAllRoles: %v", err)
+	}
+
+	// Cache sadece ilk sayfayı tutar; toplam ve sayfa sayısı yine tüm rollere göre hesaplanmalı
+	n, pagination := getRoles(t, "page=1&limit=10")
+	if n != 10 {
+		t.Errorf("roles = %d, want 10", n)
+	}
+	if pagination["total"] != float64(25) || pagination["total_pages"] != float64(3) || pagination["has_more"] != true {
+		t.Errorf("pagination = %v, want total 25, total_pages 3, has_more true", pagination)
+	}
+}
+
+var historyRoleID = uuid.MustParse("3f2a1b0c-9d8e-4f7a-8b6c-5d4e3f2a1b0c")
+
+// historyDB - historyRoleID'li tek rolü ve verilen son versiyonu dönen sahte DB
+func historyDB(t *testing.T, lastVersion int64) *dbtest.DB {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	db.Rows(`FROM "roles"`, []string{"id", "name", "description"},
+		[]driver.Value{historyRoleID.String(), "editor", "Edits content"})
+	db.Rows("MAX(version)", []string{"coalesce"}, []driver.Value{lastVersion})
+	return db
+}
+
+func updateRole(t *testing.T, body string) (int, fiber.Map) {
+	t.Helper()
+
+	app := traceApp()
+	app.Put("/roles/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "admin-1")
+		return c.Next()
+	}, UpdateRole)
+
+	req := httptest.NewRequest(fiber.MethodPut, "/roles/"+historyRoleID.String(), strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("PUT /roles: %v", err)
+	}
+	return resp.StatusCode, decodeJSON(t, resp)
+}
+
+func TestUpdateRoleRecordsHistory(t *testing.T) {
+	db := historyDB(t, 2)
+
+	if status, body := updateRole(t, `{"name":"publisher","description":"Edits content"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d: %v", status, body)
+	}
+
+	inserts := db.Statements(`INSERT INTO "role_histories"`)
+	if len(inserts) != 1 {
+		t.Fatalf("history inserts = %d, want 1", len(inserts))
+	}
+	insert := inserts[0]
+	if !insert.InTx {
+		t.Error("history was written outside the update transaction")
+	}
+	// Versiyon son versiyondan bir fazla; önceki ve yeni ad, değişen alan ve değiştiren kaydedilir
+	for _, want := range []string{historyRoleID.String(), "3", "editor", "publisher", "name", "admin-1"} {
+		found := false
+		for _, arg := range insert.Args {
+			if fmt.Sprint(arg) == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("history insert args %v missing %v", insert.Args, want)
+		}
+	}
+	if updates := db.Statements(`UPDATE "roles"`); len(updates) != 1 || !updates[0].InTx {
+		t.Errorf("role update statements = %v, want one inside the transaction", updates)
+	}
+	if db.Commits() != 1 {
+		t.Errorf("commits = %d, want 1", db.Commits())
+	}
+}
+
+func TestUpdateRoleWithoutChangesSkipsHistory(t *testing.T) {
+	db := historyDB(t, 0)
+
+	if status, body := updateRole(t, `{"name":"editor"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d: %v", status, body)
+	}
+	if inserts := db.Statements(`INSERT INTO "role_histories"`); len(inserts) != 0 {
+		t.Errorf("history inserts = %d, want 0 for an unchanged role", len(inserts))
+	}
+}
+
+func TestRenameRoleInvalidatesCachedNames(t *testing.T) {
+	redis := cachetest.Start(t)
+	db := historyDB(t, 0)
+
+	previous := cacheService
+	SetCacheService(services.NewCacheService(zap.NewNop()))
+	t.Cleanup(func() { SetCacheService(previous) })
+
+	userID := uuid.NewString()
+	stale := []string{
+		"all_roles",
+		services.RoleCachePrefix + historyRoleID.String(),
+		services.UserCachePrefix + userID,
+		services.UserRolePrefix + userID,
+	}
+	for _, key := range stale {
+		redis.Set(key, `{"role":{"name":"editor"}}`)
+	}
+	redis.Set("session:admin-1", "{}")
+
+	if status, body := updateRole(t, `{"name":"publisher"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d: %v", status, body)
+	}
+
+	// Kullanıcılar role_id ile bağlı kalır; sadece rol satırı güncellenir
+	if updates := db.Statements(`UPDATE "users"`); len(updates) != 0 {
+		t.Errorf("rename touched users: %v", updates)
+	}
+	for _, key := range stale {
+		if redis.Exists(key) {
+			t.Errorf("%s still holds the old role name", key)
+		}
+	}
+	if !redis.Exists("session:admin-1") {
+		t.Error("unrelated keys were deleted")
+	}
+}
+
+func TestGetRoleHistory(t *testing.T) {
+	app := traceApp()
+	app.Get("/roles/:id/history", GetRoleHistory)
+
+	t.Run("unknown role", func(t *testing.T) {
+		dbtest.Open(t)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/roles/"+uuid.NewString()+"/history", nil))
+		if err != nil {
+			t.Fatalf("GET history: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("status = %d, want 404", resp.StatusCode)
+		}
+	})
+
+	t.Run("newest first", func(t *testing.T) {
+		db := dbtest.Open(t)
+		db.Rows(`FROM "role_histories"`, []string{"version", "previous_name", "name", "changed_fields"},
+			[]driver.Value{int64(2), "publisher", "author", "name"},
+			[]driver.Value{int64(1), "editor", "publisher", "name"},
+		)
+		db.Rows(`count(*) FROM "role_histories"`, []string{"count"}, []driver.Value{int64(2)})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/roles/"+historyRoleID.String()+"/history", nil))
+		if err != nil {
+			t.Fatalf("GET history: %v", err)
+		}
+		body := decodeJSON(t, resp)
+		history, _ := body["history"].([]interface{})
+		if len(history) != 2 {
+			t.Fatalf("history = %v", body)
+		}
+		first := history[0].(map[string]interface{})
+		if first["version"] != float64(2) || first["previous_name"] != "publisher" || first["name"] != "author" {
+			t.Errorf("first entry = %v", first)
+		}
+		if list := db.Statements(`SELECT * FROM "role_histories"`); len(list) != 1 || !strings.Contains(list[0].SQL, "ORDER BY version DESC") {
+			t.Errorf("history query = %v, want ordered by version DESC", list)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fiber-app/pkg/database/dbtest"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// softDeleteDB - deleted_at'i tutan, GORM'un soft delete sorgularına göre filtreleyen sahte users tablosu
+type softDeleteDB struct {
+	mu      sync.Mutex
+	ids     []string
+	deleted map[string]time.Time
+}
+
+func newSoftDeleteDB(t *testing.T, n int) (*softDeleteDB, *dbtest.DB) {
+	t.Helper()
+
+	users := &softDeleteDB{deleted: make(map[string]time.Time)}
+	for i := 0; i < n; i++ {
+		users.ids = append(users.ids, uuid.NewString())
+	}
+
+	db := dbtest.Open(t)
+	db.HandleQuery(`FROM "users"`, func(query string, args []driver.Value) dbtest.Result {
+		rows := users.visible(query, args)
+		if strings.Contains(query, "count(*)") {
+			return dbtest.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(len(rows))}}}
+		}
+		return dbtest.Result{Columns: []string{"id", "name", "email", "deleted_at"}, Rows: rows}
+	})
+	// Soft delete: UPDATE ... SET "deleted_at"=$1 WHERE "users"."id" = $2 AND "users"."deleted_at" IS NULL
+	db.HandleExec(`UPDATE "users" SET "deleted_at"`, func(query string, args []driver.Value) int64 {
+		id := fmt.Sprint(args[len(args)-1])
+		users.mu.Lock()
+		defer users.mu.Unlock()
+
+		_, isDeleted := users.deleted[id]
+		switch {
+		case args[0] == nil && isDeleted:
+			delete(users.deleted, id)
+			return 1
+		case args[0] != nil && !isDeleted && users.exists(id):
+			users.deleted[id] = time.Now()
+			return 1
+		}
+		return 0
+	})
+
+	return users, db
+}
+
+func (u *softDeleteDB) exists(id string) bool {
+	for _, existing := range u.ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// visible - Sorgunun göreceği satırlar; deleted_at IS NULL koşulu yoksa silinenler de döner
+func (u *softDeleteDB) visible(query string, args []driver.Value) [][]driver.Value {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	scoped := strings.Contains(query, `"users"."deleted_at" IS NULL`)
+	var rows [][]driver.Value
+	for i, id := range u.ids {
+		if len(args) > 0 && fmt.Sprint(args[0]) != id {
+			continue
+		}
+		var deletedAt driver.Value
+		if at, ok := u.deleted[id]; ok {
+			if scoped {
+				continue
+			}
+			deletedAt = at
+		}
+		rows = append(rows, []driver.Value{id, fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i), deletedAt})
+	}
+	return rows
+}
+
+func softDeleteApp() *fiber.App {
+	app := traceApp()
+	app.Get("/users", GetUsers)
+	app.Get("/users/:id", GetUser)
+	app.Delete("/users/:id", DeleteUser)
+	app.Post("/users/:id/restore", RestoreUser)
+	return app
+}
+
+func call(t *testing.T, app *fiber.App, method, path string) (int, fiber.Map) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(method, path, nil))
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp.StatusCode, decodeJSON(t, resp)
+}
+
+// listedIDs - GET /users yanıtındaki kullanıcı id'leri
+func listedIDs(t *testing.T, app *fiber.App, query string) []string {
+	t.Helper()
+
+	status, body := call(t, app, fiber.MethodGet, "/users"+query)
+	if status != fiber.StatusOK {
+		t.Fatalf("GET /users%s status = %d: %v", query, status, body)
+	}
+	var ids []string
+	list, _ := body["users"].([]interface{})
+	for _, u := range list {
+		ids = append(ids, u.(map[string]interface{})["id"].(string))
+	}
+	return ids
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	users, _ := newSoftDeleteDB(t, 3)
+	app := softDeleteApp()
+	target := users.ids[1]
+
+	if status, body := call(t, app, fiber.MethodDelete, "/users/"+target); status != fiber.StatusOK {
+		t.Fatalf("DELETE status = %d: %v", status, body)
+	}
+
+	if ids := listedIDs(t, app, ""); containsID(ids, target) || len(ids) != 2 {
+		t.Errorf("list after delete = %v, want the deleted user excluded", ids)
+	}
+	if ids := listedIDs(t, app, "?include_deleted=true"); !containsID(ids, target) || len(ids) != 3 {
+		t.Errorf("list with include_deleted = %v, want all 3 users", ids)
+	}
+	if status, _ := call(t, app, fiber.MethodGet, "/users/"+target); status != fiber.StatusNotFound {
+		t.Errorf("GET deleted user status = %d, want 404", status)
+	}
+	status, body := call(t, app, fiber.MethodGet, "/users/"+target+"?include_deleted=true")
+	if status != fiber.StatusOK {
+		t.Fatalf("GET deleted user with include_deleted status = %d, want 200", status)
+	}
+	if user, _ := body["user"].(map[string]interface{}); user["deleted_at"] == nil {
+		t.Errorf("deleted user has no deleted_at: %v", user)
+	}
+
+	if status, body := call(t, app, fiber.MethodPost, "/users/"+target+"/restore"); status != fiber.StatusOK {
+		t.Fatalf("restore status = %d: %v", status, body)
+	}
+	if ids := listedIDs(t, app, ""); !containsID(ids, target) || len(ids) != 3 {
+		t.Errorf("list after restore = %v, want the user back", ids)
+	}
+}
+
+func TestDeleteIsSoft(t *testing.T) {
+	users, db := newSoftDeleteDB(t, 1)
+	app := softDeleteApp()
+
+	call(t, app, fiber.MethodDelete, "/users/"+users.ids[0])
+	if stmts := db.Statements(`DELETE FROM "users"`); len(stmts) != 0 {
+		t.Errorf("user hard-deleted: %s", stmts[0].SQL)
+	}
+	if status, _ := call(t, app, fiber.MethodDelete, "/users/"+users.ids[0]); status != fiber.StatusNotFound {
+		t.Errorf("second DELETE status = %d, want 404", status)
+	}
+}
+
+func TestRestoreUserErrors(t *testing.T) {
+	t.Run("not deleted", func(t *testing.T) {
+		users, _ := newSoftDeleteDB(t, 1)
+		status, body := call(t, softDeleteApp(), fiber.MethodPost, "/users/"+users.ids[0]+"/restore")
+		if status != fiber.StatusConflict || body["code"] != "user_not_deleted" {
+			t.Errorf("status = %d, code = %v, want 409 user_not_deleted", status, body["code"])
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		newSoftDeleteDB(t, 1)
+		status, _ := call(t, softDeleteApp(), fiber.MethodPost, "/users/"+uuid.NewString()+"/restore")
+		if status != fiber.StatusNotFound {
+			t.Errorf("status = %d, want 404", status)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		newSoftDeleteDB(t, 1)
+		status, _ := call(t, softDeleteApp(), fiber.MethodPost, "/users/not-a-uuid/restore")
+		if status != fiber.StatusBadRequest {
+			t.Errorf("status = %d, want 400", status)
+		}
+	})
+
+	t.Run("email reused", func(t *testing.T) {
+		users, db := newSoftDeleteDB(t, 1)
+		app := softDeleteApp()
+		call(t, app, fiber.MethodDelete, "/users/"+users.ids[0])
+
+		// Silindikten sonra aynı email ile yeni kullanıcı oluşturulmuş
+		db.Fail("deleted_at IS NOT NULL", errors.New(`ERROR: duplicate key value violates unique constraint "idx_users_email_active"`))
+		status, body := call(t, app, fiber.MethodPost, "/users/"+users.ids[0]+"/restore")
+		if status != fiber.StatusConflict || body["code"] != "email_taken" {
+			t.Errorf("status = %d, code = %v, want 409 email_taken", status, body["code"])
+		}
+	})
+}
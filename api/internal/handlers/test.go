@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fiber-app/pkg/i18n"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -64,10 +65,7 @@ func TestPost(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Invalid JSON body",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidJSON)
 	}
 
 	zapLogger.Info("Test POST endpoint çağrıldı",
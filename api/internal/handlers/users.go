@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"errors"
 	"fiber-app/internal/models"
 	"fiber-app/pkg/database"
+	"fiber-app/pkg/i18n"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetUsers - Tüm kullanıcıları listele
@@ -21,8 +25,13 @@ import (
 // @Produce json
 // @Param page query int false "Sayfa numarası" default(1)
 // @Param limit query int false "Sayfa başına kayıt sayısı" default(10)
-// @Param search query string false "Arama terimi (isim veya email)"
+// @Param search query string false "Arama terimi (isim veya email), sonuçlar benzerliğe göre sıralanır"
+// @Param sort query string false "Sıralama alanı (created_at, name, email, updated_at)" default(created_at)
+// @Param order query string false "Sıralama yönü (asc, desc)" default(desc)
+// @Param cursor query string false "Keyset pagination; ilk sayfa için boş gönderilir, sonrakiler için next_cursor"
+// @Param include_deleted query bool false "Silinmiş (soft delete) kullanıcıları da listele"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/users [get]
 func GetUsers(c *fiber.Ctx) error {
@@ -32,6 +41,8 @@ func GetUsers(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	search := c.Query("search", "")
+	sortBy := c.Query("sort")
+	sortOrder := strings.ToLower(c.Query("order"))
 
 	if page < 1 {
 		page = 1
@@ -40,8 +51,6 @@ func GetUsers(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	offset := (page - 1) * limit
-
 	zapLogger.Info("Users listesi istendi",
 		zap.String("trace_id", traceID),
 		zap.Int("page", page),
@@ -52,11 +61,49 @@ func GetUsers(c *fiber.Ctx) error {
 	var users []models.User
 	var total int64
 
-	query := database.DB.Model(&models.User{}).Preload("Role")
+	// Sıralama parametreleri sadece izin verilen kolon/yönlerle kabul edilir
+	explicitSort := sortBy != "" || sortOrder != ""
+	order, ok := userListOrder(sortBy, sortOrder)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":         i18n.T(c, i18n.CodeInvalidSort),
+			"code":          i18n.CodeInvalidSort,
+			"allowed_sort":  userSortColumns,
+			"allowed_order": []string{"asc", "desc"},
+			"trace_id":      traceID,
+		})
+	}
+
+	query := requestDB(c).Model(&models.User{}).Preload("Role")
+	if c.QueryBool("include_deleted") {
+		query = query.Unscoped()
+	}
 
 	// Arama filtresi
 	if search != "" {
-		query = query.Where("name ILIKE ? OR email ILIKE ?", "%"+search+"%", "%"+search+"%")
+		pattern := "%" + search + "%"
+		if database.TrigramSearchEnabled() {
+			// Alt string eşleşmeleri + yazım hatalı benzer sonuçlar
+			query = query.Where("name ILIKE ? OR email ILIKE ? OR name % ? OR email % ?", pattern, pattern, search, search)
+			// Açıkça sıralama istenmediyse en benzer olan önce
+			if !explicitSort {
+				order = clause.OrderBy{Expression: clause.Expr{
+					SQL:  "GREATEST(similarity(name, ?), similarity(email, ?)) DESC, created_at DESC",
+					Vars: []interface{}{search, search},
+				}}
+			}
+		} else {
+			query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+		}
+	}
+
+	// cursor parametresi varsa (boş olsa bile) offset yerine keyset pagination kullanılır
+	if c.Context().QueryArgs().Has("cursor") {
+		// Keyset sadece default sıralama (created_at DESC, id DESC) ile çalışır
+		if (sortBy != "" && sortBy != "created_at") || sortOrder == "asc" {
+			return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidSort)
+		}
+		return listUsersByCursor(c, query, c.Query("cursor"), limit)
 	}
 
 	// Toplam sayı
@@ -65,36 +112,139 @@ func GetUsers(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
+	// Sayfa numarasını mevcut sayfa sayısına göre sınırla
+	page = clampPage(page, limit, total)
+	offset := (page - 1) * limit
+
 	// Sayfalama ile veri çek
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error; err != nil {
+	if err := query.Clauses(order).Offset(offset).Limit(limit).Find(&users).Error; err != nil {
 		zapLogger.Error("Users listesi hatası",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	return c.JSON(fiber.Map{
-		"users": users,
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-		"trace_id": traceID,
+		"users":      users,
+		"pagination": paginationMeta(page, limit, total),
+		"trace_id":   traceID,
+	})
+}
+
+// listUsersByCursor - (created_at, id) keyset'i ile sonraki sayfayı döner
+// Sayfalar arası eklenen/silinen kayıtlar atlanma veya tekrar gösterilmeye yol açmaz
+func listUsersByCursor(c *fiber.Ctx, query *gorm.DB, cursor string, limit int) error {
+	traceID := getTraceID(c)
+
+	if cursor != "" {
+		after, err := decodeUserCursor(cursor)
+		if err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidCursor)
+		}
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	order, _ := userListOrder("created_at", "desc")
+
+	// Bir fazla kayıt istenir; gelirse sonraki sayfa vardır (tam dolu son sayfada has_more false kalır)
+	var users []models.User
+	if err := query.Clauses(order).Limit(limit + 1).Find(&users).Error; err != nil {
+		zapLogger.Error("Users listesi hatası",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	nextCursor := ""
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = encodeUserCursor(users[len(users)-1])
+	}
+
+	return c.JSON(fiber.Map{
+		"users":       users,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+		"trace_id":    traceID,
 	})
 }
 
+// userCursor - Keyset pagination'da son görülen kaydın sıralama anahtarı
+type userCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// encodeUserCursor - Kaydın (created_at, id) değerini opaque cursor'a çevirir
+func encodeUserCursor(user models.User) string {
+	raw := user.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + user.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeUserCursor - encodeUserCursor ile üretilmiş cursor'ı çözer
+func decodeUserCursor(cursor string) (userCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return userCursor{}, err
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return userCursor{}, errors.New("malformed cursor")
+	}
+
+	var uc userCursor
+	if uc.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return userCursor{}, err
+	}
+	if uc.ID, err = uuid.Parse(id); err != nil {
+		return userCursor{}, err
+	}
+
+	return uc, nil
+}
+
+// userSortColumns - GetUsers'ta sıralanabilecek kolonlar
+var userSortColumns = []string{"created_at", "name", "email", "updated_at"}
+
+// userListOrder - sort/order parametrelerini ORDER BY'a çevirir (default: created_at DESC)
+// Kolon adı SQL'e kullanıcı girdisinden değil whitelist'ten gelir
+func userListOrder(sortBy, sortOrder string) (clause.OrderBy, bool) {
+	column := "created_at"
+	if sortBy != "" {
+		column = ""
+		for _, allowed := range userSortColumns {
+			if sortBy == allowed {
+				column = allowed
+				break
+			}
+		}
+		if column == "" {
+			return clause.OrderBy{}, false
+		}
+	}
+
+	desc := true
+	switch sortOrder {
+	case "", "desc":
+	case "asc":
+		desc = false
+	default:
+		return clause.OrderBy{}, false
+	}
+
+	// Aynı değerli kayıtlarda sayfalar arası tutarlı sıralama için id ile
+	return clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: column}, Desc: desc},
+		{Column: clause.Column{Name: "id"}, Desc: desc},
+	}}, true
+}
+
 // GetUser - Tek kullanıcı getir
 // @Summary Kullanıcı detayı
 // @Description ID ile kullanıcı detayını getir
@@ -102,6 +252,7 @@ func GetUsers(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID (UUID)"
+// @Param include_deleted query bool false "Silinmiş (soft delete) kullanıcıyı da getir"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
@@ -112,19 +263,13 @@ func GetUser(c *fiber.Ctx) error {
 
 	userID := c.Params("id")
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "User ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUserIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz User ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidUserID)
 	}
 
 	zapLogger.Info("User detayı istendi",
@@ -132,9 +277,12 @@ func GetUser(c *fiber.Ctx) error {
 		zap.String("user_id", userID),
 	)
 
+	// Silinmiş kullanıcılar cache'lenmez, direkt database'den okunur
+	includeDeleted := c.QueryBool("include_deleted")
+
 	// Önce cache'den kontrol et
-	if cacheService != nil {
-		if cachedUser, err := cacheService.GetUser(id); err == nil {
+	if cacheService != nil && !includeDeleted {
+		if cachedUser, err := requestCache(c).GetUser(id); err == nil {
 			zapLogger.Info("User cache'den getirildi",
 				zap.String("trace_id", traceID),
 				zap.String("user_id", userID),
@@ -148,13 +296,15 @@ func GetUser(c *fiber.Ctx) error {
 	}
 
 	// Cache'de yoksa database'den getir
+	db := requestDB(c)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+
 	var user models.User
-	if err := database.DB.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+	if err := db.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "User bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeUserNotFound)
 		}
 
 		zapLogger.Error("User getirme hatası",
@@ -162,15 +312,12 @@ func GetUser(c *fiber.Ctx) error {
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Cache'e kaydet
-	if cacheService != nil {
-		if err := cacheService.SetUser(&user); err != nil {
+	if cacheService != nil && !user.DeletedAt.Valid {
+		if err := requestCache(c).SetUser(&user); err != nil {
 			zapLogger.Warn("User cache'e kaydedilemedi",
 				zap.String("trace_id", traceID),
 				zap.String("user_id", userID),
@@ -196,6 +343,7 @@ func GetUser(c *fiber.Ctx) error {
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/users [post]
 func CreateUser(c *fiber.Ctx) error {
@@ -207,40 +355,29 @@ func CreateUser(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz JSON formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidJSON)
 	}
 
 	// Basit validasyon
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Name alanı gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeNameRequired)
 	}
 
 	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Email alanı gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeEmailRequired)
+	}
+
+	if fe := userFieldError(req.Name, req.Email); fe != nil {
+		return fieldErrorResponse(c, fe)
 	}
 
 	// Role kontrolü
 	var role models.Role
-	if err := database.DB.First(&role, "id = ?", req.RoleID).Error; err != nil {
+	if err := requestDB(c).First(&role, "id = ?", req.RoleID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":    "Geçersiz role ID",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUnknownRole)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	zapLogger.Info("Yeni user oluşturuluyor",
@@ -262,7 +399,7 @@ func CreateUser(c *fiber.Ctx) error {
 		user.Active = *req.Active
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
+	if err := requestDB(c).Create(&user).Error; err != nil {
 		zapLogger.Error("User oluşturma hatası",
 			zap.String("trace_id", traceID),
 			zap.Error(err),
@@ -270,20 +407,14 @@ func CreateUser(c *fiber.Ctx) error {
 
 		// Email unique constraint hatası
 		if strings.Contains(err.Error(), "duplicate key") && strings.Contains(err.Error(), "email") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error":    "Bu email adresi zaten kullanımda",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusConflict, i18n.CodeEmailTaken)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Role bilgisini yükle
-	database.DB.Preload("Role").First(&user, user.ID)
+	requestDB(c).Preload("Role").First(&user, user.ID)
 
 	zapLogger.Info("User başarıyla oluşturuldu",
 		zap.String("trace_id", traceID),
@@ -309,6 +440,7 @@ func CreateUser(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/users/{id} [put]
 func UpdateUser(c *fiber.Ctx) error {
@@ -316,19 +448,13 @@ func UpdateUser(c *fiber.Ctx) error {
 
 	userID := c.Params("id")
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "User ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUserIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz User ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidUserID)
 	}
 
 	var req models.UpdateUserRequest
@@ -337,10 +463,18 @@ func UpdateUser(c *fiber.Ctx) error {
 			zap.String("trace_id", traceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz JSON formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidJSON)
+	}
+
+	var name, email string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Email != nil {
+		email = *req.Email
+	}
+	if fe := userFieldError(name, email); fe != nil {
+		return fieldErrorResponse(c, fe)
 	}
 
 	zapLogger.Info("User güncelleniyor",
@@ -350,12 +484,9 @@ func UpdateUser(c *fiber.Ctx) error {
 
 	// Önce user'ın var olup olmadığını kontrol et
 	var user models.User
-	if err := database.DB.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).Preload("Role").First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "User bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeUserNotFound)
 		}
 
 		zapLogger.Error("User bulma hatası",
@@ -363,10 +494,7 @@ func UpdateUser(c *fiber.Ctx) error {
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Güncelleme verilerini hazırla
@@ -387,30 +515,21 @@ func UpdateUser(c *fiber.Ctx) error {
 	if req.RoleID != nil {
 		// Role kontrolü
 		var role models.Role
-		if err := database.DB.First(&role, "id = ?", *req.RoleID).Error; err != nil {
+		if err := requestDB(c).First(&role, "id = ?", *req.RoleID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error":    "Geçersiz role ID",
-					"trace_id": traceID,
-				})
+				return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUnknownRole)
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":    "Database hatası",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 		}
 		updates["role_id"] = *req.RoleID
 	}
 
 	if len(updates) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Güncellenecek alan bulunamadı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeNoFieldsToUpdate)
 	}
 
 	// Güncelle
-	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+	if err := requestDB(c).Model(&user).Updates(updates).Error; err != nil {
 		zapLogger.Error("User güncelleme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("user_id", userID),
@@ -419,34 +538,25 @@ func UpdateUser(c *fiber.Ctx) error {
 
 		// Email unique constraint hatası
 		if strings.Contains(err.Error(), "duplicate key") && strings.Contains(err.Error(), "email") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error":    "Bu email adresi zaten kullanımda",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusConflict, i18n.CodeEmailTaken)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Güncellenmiş user'ı getir
-	if err := database.DB.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).Preload("Role").First(&user, "id = ?", id).Error; err != nil {
 		zapLogger.Error("Güncellenmiş user getirme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Cache'i invalidate et
 	if cacheService != nil {
-		if err := cacheService.InvalidateUserCaches(id); err != nil {
+		if err := requestCache(c).InvalidateUserCaches(id); err != nil {
 			zapLogger.Warn("User cache invalidation başarısız",
 				zap.String("trace_id", traceID),
 				zap.String("user_id", userID),
@@ -469,7 +579,7 @@ func UpdateUser(c *fiber.Ctx) error {
 
 // DeleteUser - Kullanıcı sil
 // @Summary Kullanıcı sil
-// @Description Kullanıcıyı sistemden sil
+// @Description Kullanıcıyı soft delete ile sil (restore endpoint'i ile geri alınabilir)
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -484,19 +594,13 @@ func DeleteUser(c *fiber.Ctx) error {
 
 	userID := c.Params("id")
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "User ID gerekli",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUserIDRequired)
 	}
 
 	// UUID kontrolü
 	id, err := uuid.Parse(userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":    "Geçersiz User ID formatı",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidUserID)
 	}
 
 	zapLogger.Info("User siliniyor",
@@ -506,12 +610,9 @@ func DeleteUser(c *fiber.Ctx) error {
 
 	// Önce user'ın var olup olmadığını kontrol et
 	var user models.User
-	if err := database.DB.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+	if err := requestDB(c).Preload("Role").First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":    "User bulunamadı",
-				"trace_id": traceID,
-			})
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeUserNotFound)
 		}
 
 		zapLogger.Error("User bulma hatası",
@@ -519,28 +620,22 @@ func DeleteUser(c *fiber.Ctx) error {
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Sil
-	if err := database.DB.Delete(&user).Error; err != nil {
+	if err := requestDB(c).Delete(&user).Error; err != nil {
 		zapLogger.Error("User silme hatası",
 			zap.String("trace_id", traceID),
 			zap.String("user_id", userID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":    "Database hatası",
-			"trace_id": traceID,
-		})
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
 	}
 
 	// Cache'i invalidate et
 	if cacheService != nil {
-		if err := cacheService.InvalidateUserCaches(id); err != nil {
+		if err := requestCache(c).InvalidateUserCaches(id); err != nil {
 			zapLogger.Warn("User cache invalidation başarısız",
 				zap.String("trace_id", traceID),
 				zap.String("user_id", userID),
@@ -559,3 +654,94 @@ func DeleteUser(c *fiber.Ctx) error {
 		"trace_id": traceID,
 	})
 }
+
+// RestoreUser - Soft delete edilmiş kullanıcıyı geri yükle
+// @Summary Kullanıcıyı geri yükle
+// @Description Silinmiş (soft delete) kullanıcıyı geri yükler
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/restore [post]
+func RestoreUser(c *fiber.Ctx) error {
+	traceID := getTraceID(c)
+
+	userID := c.Params("id")
+	if userID == "" {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeUserIDRequired)
+	}
+
+	// UUID kontrolü
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidUserID)
+	}
+
+	zapLogger.Info("User geri yükleniyor",
+		zap.String("trace_id", traceID),
+		zap.String("user_id", userID),
+	)
+
+	result := requestDB(c).Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		zapLogger.Error("User geri yükleme hatası",
+			zap.String("trace_id", traceID),
+			zap.String("user_id", userID),
+			zap.Error(result.Error),
+		)
+
+		// Silindikten sonra aynı email ile yeni kullanıcı oluşturulmuş
+		if strings.Contains(result.Error.Error(), "duplicate key") && strings.Contains(result.Error.Error(), "email") {
+			return errorResponse(c, fiber.StatusConflict, i18n.CodeEmailTaken)
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	var user models.User
+	if err := requestDB(c).Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorResponse(c, fiber.StatusNotFound, i18n.CodeUserNotFound)
+		}
+
+		zapLogger.Error("User getirme hatası",
+			zap.String("trace_id", traceID),
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	// Kullanıcı zaten silinmemiş
+	if result.RowsAffected == 0 {
+		return errorResponse(c, fiber.StatusConflict, i18n.CodeUserNotDeleted)
+	}
+
+	// Cache'i invalidate et
+	if cacheService != nil {
+		if err := requestCache(c).InvalidateUserCaches(id); err != nil {
+			zapLogger.Warn("User cache invalidation başarısız",
+				zap.String("trace_id", traceID),
+				zap.String("user_id", userID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	zapLogger.Info("User başarıyla geri yüklendi",
+		zap.String("trace_id", traceID),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(fiber.Map{
+		"message":  "User başarıyla geri yüklendi",
+		"user":     user,
+		"trace_id": traceID,
+	})
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/i18n"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Tek istekte import edilebilecek maksimum satır
+	maxImportRows = 1000
+	// Her transaction'da upsert edilecek satır sayısı
+	importBatchSize = 100
+)
+
+var errImportTooLarge = fmt.Errorf("en fazla %d satır import edilebilir", maxImportRows)
+
+// ImportUsers - Kullanıcıları toplu import et
+// @Summary Kullanıcıları toplu import et
+// @Description JSON dizisi veya CSV ile kullanıcıları validasyon sonrası email'e göre upsert eder. dry_run=true ile sadece validasyon yapar
+// @Tags Users
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param dry_run query bool false "Sadece validasyon yap, kaydetme" default(false)
+// @Param users body []models.ImportUserRow true "Import edilecek kullanıcılar"
+// @Success 200 {object} models.BulkResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/import [post]
+func ImportUsers(c *fiber.Ctx) error {
+	traceID := getTraceID(c)
+	dryRun := c.QueryBool("dry_run", false)
+
+	rows, err := parseImportRows(c)
+	if err != nil {
+		if errors.Is(err, errImportTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error":    i18n.T(c, i18n.CodeImportTooLarge, maxImportRows),
+				"code":     i18n.CodeImportTooLarge,
+				"trace_id": traceID,
+			})
+		}
+
+		zapLogger.Error("User import body parse hatası",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeInvalidImportFormat)
+	}
+
+	if len(rows) == 0 {
+		return errorResponse(c, fiber.StatusBadRequest, i18n.CodeImportEmpty)
+	}
+
+	zapLogger.Info("User import başladı",
+		zap.String("trace_id", traceID),
+		zap.Int("rows", len(rows)),
+		zap.Bool("dry_run", dryRun),
+	)
+
+	// Geçerli role ID'lerini tek sorguda al
+	var roleIDs []uuid.UUID
+	if err := requestDB(c).Model(&models.Role{}).Pluck("id", &roleIDs).Error; err != nil {
+		zapLogger.Error("Role listesi alınamadı",
+			zap.String("trace_id", traceID),
+			zap.Error(err),
+		)
+		return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeDatabaseError)
+	}
+
+	result := validateImportRows(rows, roleIDs, i18n.Language(c.Get(fiber.HeaderAcceptLanguage)))
+	result.DryRun = dryRun
+
+	if dryRun || result.Valid == 0 {
+		return c.JSON(fiber.Map{
+			"result":   result,
+			"trace_id": traceID,
+		})
+	}
+
+	// Sadece geçerli satırlar kaydedilir
+	users := make([]models.User, 0, result.Valid)
+	for i, row := range rows {
+		if !result.Rows[i].Valid {
+			continue
+		}
+
+		user := models.User{
+			Name:   strings.TrimSpace(row.Name),
+			Email:  strings.TrimSpace(row.Email),
+			Age:    row.Age,
+			Active: true,
+			RoleID: row.RoleID,
+		}
+		if row.Active != nil {
+			user.Active = *row.Active
+		}
+		users = append(users, user)
+	}
+
+	imported, err := upsertUsers(requestDB(c), users)
+	result.Imported = imported
+	if err != nil {
+		zapLogger.Error("User import hatası",
+			zap.String("trace_id", traceID),
+			zap.Int("imported", imported),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":    i18n.T(c, i18n.CodeDatabaseError),
+			"code":     i18n.CodeDatabaseError,
+			"result":   result,
+			"trace_id": traceID,
+		})
+	}
+
+	invalidateImportedUsers(c, users)
+
+	zapLogger.Info("User import tamamlandı",
+		zap.String("trace_id", traceID),
+		zap.Int("imported", imported),
+		zap.Int("invalid", result.Invalid),
+	)
+
+	return c.JSON(fiber.Map{
+		"result":   result,
+		"trace_id": traceID,
+	})
+}
+
+// parseImportRows - Body'yi Content-Type'a göre JSON dizisi veya CSV olarak parse eder
+func parseImportRows(c *fiber.Ctx) ([]models.ImportUserRow, error) {
+	var rows []models.ImportUserRow
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		parsed, err := parseImportCSV(strings.NewReader(string(c.Body())))
+		if err != nil {
+			return nil, err
+		}
+		rows = parsed
+	} else if err := c.BodyParser(&rows); err != nil {
+		return nil, err
+	}
+
+	if len(rows) > maxImportRows {
+		return nil, errImportTooLarge
+	}
+
+	return rows, nil
+}
+
+// parseImportCSV - Başlık satırı name,email,age,active,role_id kolonlarını içeren CSV
+func parseImportCSV(r io.Reader) ([]models.ImportUserRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []models.ImportUserRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, errImportTooLarge
+		}
+
+		row := models.ImportUserRow{
+			Name:  field(record, "name"),
+			Email: field(record, "email"),
+		}
+		if age := field(record, "age"); age != "" {
+			// Geçersiz yaş validasyonda yakalanır
+			if row.Age, err = strconv.Atoi(age); err != nil {
+				row.Age = -1
+			}
+		}
+		if active := field(record, "active"); active != "" {
+			if value, err := strconv.ParseBool(active); err == nil {
+				row.Active = &value
+			}
+		}
+		if roleID := field(record, "role_id"); roleID != "" {
+			row.RoleID, _ = uuid.Parse(roleID)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// validateImportRows - Her satırı validate eder, import içindeki tekrar eden email'leri yakalar
+// Satır hataları lang dilinde mesaj ve dilden bağımsız kod olarak döner
+func validateImportRows(rows []models.ImportUserRow, roleIDs []uuid.UUID, lang string) models.BulkResult {
+	validRoles := make(map[uuid.UUID]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		validRoles[id] = true
+	}
+
+	seenEmails := make(map[string]int, len(rows))
+	result := models.BulkResult{
+		Total: len(rows),
+		Rows:  make([]models.BulkRowResult, len(rows)),
+	}
+
+	for i, row := range rows {
+		email := strings.TrimSpace(row.Email)
+		emailKey := strings.ToLower(email)
+		rowResult := models.BulkRowResult{Row: i + 1, Email: email}
+		addError := func(code i18n.Code, args ...interface{}) {
+			rowResult.Errors = append(rowResult.Errors, i18n.Message(lang, code, args...))
+			rowResult.Codes = append(rowResult.Codes, string(code))
+		}
+
+		if strings.TrimSpace(row.Name) == "" {
+			addError(i18n.CodeNameRequired)
+		}
+
+		if email == "" {
+			addError(i18n.CodeEmailRequired)
+		} else if !isPlainEmail(email) {
+			addError(i18n.CodeInvalidEmail)
+		} else if first, ok := seenEmails[emailKey]; ok {
+			addError(i18n.CodeDuplicateImportRow, first)
+		} else {
+			seenEmails[emailKey] = i + 1
+		}
+
+		if fe := userFieldError(row.Name, email); fe != nil {
+			addError(i18n.CodeFieldTooLong, fe.Field, fe.MaxLength)
+		}
+
+		if row.Age < 0 || row.Age > 150 {
+			addError(i18n.CodeAgeOutOfRange, 0, 150)
+		}
+
+		if !validRoles[row.RoleID] {
+			addError(i18n.CodeUnknownRole)
+		}
+
+		rowResult.Valid = len(rowResult.Errors) == 0
+		result.Rows[i] = rowResult
+
+		if rowResult.Valid {
+			result.Valid++
+		} else {
+			result.Invalid++
+		}
+	}
+
+	return result
+}
+
+// isPlainEmail - Sadece çıplak adres kabul edilir; "Foo <a@b.com>" gibi display name'li formlar reddedilir
+func isPlainEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Name == "" && addr.Address == email
+}
+
+// upsertUsers - Kullanıcıları email'e göre batch'ler halinde, her batch kendi transaction'ında upsert eder
+func upsertUsers(db *gorm.DB, users []models.User) (int, error) {
+	imported := 0
+
+	for start := 0; start < len(users); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(users) {
+			end = len(users)
+		}
+		batch := users[start:end]
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Email unique index'i partial (deleted_at IS NULL); conflict target'ı da aynı koşulu içermeli
+			return tx.Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "email"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"name", "age", "active", "role_id", "updated_at"}),
+			}).Create(&batch).Error
+		})
+		if err != nil {
+			return imported, err
+		}
+
+		imported += len(batch)
+	}
+
+	return imported, nil
+}
+
+// invalidateImportedUsers - Güncellenen mevcut kullanıcıların cache'ini temizler
+func invalidateImportedUsers(c *fiber.Ctx, users []models.User) {
+	if cacheService == nil {
+		return
+	}
+
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+
+	var ids []uuid.UUID
+	if err := requestDB(c).Model(&models.User{}).Where("email IN ?", emails).Pluck("id", &ids).Error; err != nil {
+		zapLogger.Warn("Import sonrası user ID'leri alınamadı",
+			zap.String("trace_id", getTraceID(c)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	cs := requestCache(c)
+	for _, id := range ids {
+		if err := cs.InvalidateUserCaches(id); err != nil {
+			zapLogger.Warn("User cache invalidation başarısız",
+				zap.String("trace_id", getTraceID(c)),
+				zap.String("user_id", id.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/database/dbtest"
+	"fiber-app/pkg/i18n"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var importRoleID = uuid.MustParse("7b0f6c2e-1a2b-4c3d-8e9f-0a1b2c3d4e5f")
+
+// importDB - Sadece importRoleID'nin tanımlı olduğu sahte veritabanı
+func importDB(t *testing.T) *dbtest.DB {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	db.Rows(`FROM "roles"`, []string{"id"}, []driver.Value{importRoleID.String()})
+	return db
+}
+
+// importUsers - Satırları JSON olarak import endpoint'ine gönderir
+func importUsers(t *testing.T, rows []models.ImportUserRow, dryRun bool) (int, models.BulkResult) {
+	t.Helper()
+	return importUsersWithLanguage(t, rows, dryRun, "")
+}
+
+func importUsersWithLanguage(t *testing.T, rows []models.ImportUserRow, dryRun bool, acceptLanguage string) (int, models.BulkResult) {
+	t.Helper()
+
+	app := traceApp()
+	app.Post("/users/import", ImportUsers)
+
+	body, _ := json.Marshal(rows)
+	req := httptest.NewRequest(fiber.MethodPost, fmt.Sprintf("/users/import?dry_run=%t", dryRun), bytes.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if acceptLanguage != "" {
+		req.Header.Set(fiber.HeaderAcceptLanguage, acceptLanguage)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	var result struct {
+		Result models.BulkResult `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return resp.StatusCode, result.Result
+}
+
+func validImportRows(n int) []models.ImportUserRow {
+	rows := make([]models.ImportUserRow, n)
+	for i := range rows {
+		rows[i] = models.ImportUserRow{
+			Name:   fmt.Sprintf("User %d", i),
+			Email:  fmt.Sprintf("user%d@example.com", i),
+			Age:    30,
+			RoleID: importRoleID,
+		}
+	}
+	return rows
+}
+
+func TestValidateImportRowsEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{"a@b.com", true},
+		{"  a@b.com  ", true},
+		{"Foo <a@b.com>", false},
+		{"<a@b.com>", false},
+		{`"Foo" <a@b.com>`, false},
+		{"a@b.com (Foo)", false},
+		{"not-an-email", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			rows := []models.ImportUserRow{{Name: "Foo", Email: tt.email, RoleID: importRoleID}}
+			result := validateImportRows(rows, []uuid.UUID{importRoleID}, i18n.DefaultLanguage)
+			if got := result.Rows[0].Valid; got != tt.valid {
+				t.Errorf("valid = %v, want %v (errors: %v)", got, tt.valid, result.Rows[0].Errors)
+			}
+		})
+	}
+}
+
+func TestImportUsersDryRunWritesNothing(t *testing.T) {
+	db := importDB(t)
+
+	status, result := importUsers(t, validImportRows(3), true)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if !result.DryRun || result.Valid != 3 || result.Imported != 0 {
+		t.Errorf("result = %+v, want dry run with 3 valid rows and nothing imported", result)
+	}
+	if inserts := db.Statements("INSERT"); len(inserts) != 0 {
+		t.Errorf("dry run executed %d inserts", len(inserts))
+	}
+}
+
+func TestImportUsersReportsRowErrors(t *testing.T) {
+	db := importDB(t)
+
+	rows := validImportRows(2)
+	rows = append(rows,
+		models.ImportUserRow{Name: "", Email: "Foo <foo@example.com>", RoleID: importRoleID},
+		models.ImportUserRow{Name: "Dup", Email: "USER0@example.com", RoleID: importRoleID},
+		models.ImportUserRow{Name: "Old", Email: "old@example.com", Age: 200, RoleID: uuid.New()},
+	)
+
+	status, result := importUsers(t, rows, false)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if result.Valid != 2 || result.Invalid != 3 || result.Imported != 2 {
+		t.Errorf("result = %+v, want 2 valid, 3 invalid, 2 imported", result)
+	}
+
+	wantErrors := []int{0, 0, 2, 1, 2}
+	for i, want := range wantErrors {
+		row := result.Rows[i]
+		if row.Row != i+1 {
+			t.Errorf("rows[%d].row = %d, want %d", i, row.Row, i+1)
+		}
+		if len(row.Errors) != want {
+			t.Errorf("row %d errors = %v, want %d", row.Row, row.Errors, want)
+		}
+	}
+
+	// Sadece geçerli satırlar yazılır
+	inserts := db.Statements(`INSERT INTO "users"`)
+	if len(inserts) != 1 {
+		t.Fatalf("inserts = %d, want 1", len(inserts))
+	}
+	for _, arg := range inserts[0].Args {
+		if arg == "old@example.com" || arg == "Foo <foo@example.com>" {
+			t.Errorf("invalid row %v was written", arg)
+		}
+	}
+}
+
+func TestImportUsersUpsertsInBatches(t *testing.T) {
+	db := importDB(t)
+
+	total := 2*importBatchSize + 50
+	status, result := importUsers(t, validImportRows(total), false)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if result.Imported != total {
+		t.Errorf("imported = %d, want %d", result.Imported, total)
+	}
+
+	inserts := db.Statements(`INSERT INTO "users"`)
+	if len(inserts) != 3 {
+		t.Fatalf("inserts = %d, want 3 batches", len(inserts))
+	}
+	for i, insert := range inserts {
+		if !insert.InTx {
+			t.Errorf("batch %d ran outside a transaction", i)
+		}
+		if !strings.Contains(insert.SQL, `ON CONFLICT ("email")`) || !strings.Contains(insert.SQL, "WHERE deleted_at IS NULL DO UPDATE") {
+			t.Errorf("batch %d is not an upsert on active emails", i)
+		}
+		if rows := strings.Count(insert.SQL, "),("); rows+1 > importBatchSize {
+			t.Errorf("batch %d has %d rows, want at most %d", i, rows+1, importBatchSize)
+		}
+	}
+	if commits := db.Commits(); commits != 3 {
+		t.Errorf("commits = %d, want one per batch", commits)
+	}
+}
+
+func TestImportRowErrorsAreLocalized(t *testing.T) {
+	importDB(t)
+
+	rows := []models.ImportUserRow{
+		{Name: "A", Email: "a@example.com", Age: 200, RoleID: importRoleID},
+		{Name: "B", Email: "a@example.com", RoleID: importRoleID},
+	}
+
+	tests := []struct {
+		acceptLanguage string
+		want           [][]string
+	}{
+		{"en", [][]string{{"Age must be between 0 and 150"}, {"Email is repeated from row 1"}}},
+		{"tr-TR,tr;q=0.9", [][]string{{"Age 0-150 arasında olmalı"}, {"Email 1. satırda tekrar ediyor"}}},
+		// Desteklenmeyen dil varsayılan dile düşer
+		{"de-DE", [][]string{{"Age must be between 0 and 150"}, {"Email is repeated from row 1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			_, result := importUsersWithLanguage(t, rows, true, tt.acceptLanguage)
+
+			for i, want := range tt.want {
+				row := result.Rows[i]
+				if fmt.Sprint(row.Errors) != fmt.Sprint(want) {
+					t.Errorf("row %d errors = %q, want %q", row.Row, row.Errors, want)
+				}
+			}
+
+			// Kodlar dilden bağımsızdır
+			if got := result.Rows[0].Codes; len(got) != 1 || got[0] != string(i18n.CodeAgeOutOfRange) {
+				t.Errorf("row 1 codes = %v", got)
+			}
+			if got := result.Rows[1].Codes; len(got) != 1 || got[0] != string(i18n.CodeDuplicateImportRow) {
+				t.Errorf("row 2 codes = %v", got)
+			}
+		})
+	}
+}
+
+func TestFieldErrorMessages(t *testing.T) {
+	fe := checkMaxLength("name", strings.Repeat("a", 11), 10)
+	if fe == nil {
+		t.Fatal("expected a field error")
+	}
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", "name must be at most 10 characters"},
+		{"tr", "name en fazla 10 karakter olabilir"},
+		{"de", "name must be at most 10 characters"},
+	}
+	for _, tt := range tests {
+		if got := fe.Message(tt.lang); got != tt.want {
+			t.Errorf("Message(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+	if got := fe.Error(); got != tests[0].want {
+		t.Errorf("Error() = %q, want the default language message", got)
+	}
+}
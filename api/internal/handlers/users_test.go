@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/database"
+	"fiber-app/pkg/database/dbtest"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var limitPattern = regexp.MustCompile(`LIMIT (\d+)`)
+
+// keysetUsersDB - (created_at DESC, id DESC) sıralı n kullanıcıyı keyset sorgusuna göre dönen sahte DB
+func keysetUsersDB(t *testing.T, n int) []models.User {
+	t.Helper()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := make([]models.User, n)
+	for i := range users {
+		users[i] = models.User{
+			ID:        uuid.New(),
+			Name:      fmt.Sprintf("User %d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: base.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	// İki kullanıcı aynı created_at'e sahip; sıra id ile belirlenir
+	if n > 3 {
+		users[3].CreatedAt = users[2].CreatedAt
+		if users[3].ID.String() > users[2].ID.String() {
+			users[2], users[3] = users[3], users[2]
+		}
+	}
+
+	db := dbtest.Open(t)
+	db.HandleQuery(`FROM "users"`, func(query string, args []driver.Value) dbtest.Result {
+		limit := len(users)
+		if m := limitPattern.FindStringSubmatch(query); m != nil {
+			limit, _ = strconv.Atoi(m[1])
+		}
+
+		result := dbtest.Result{Columns: []string{"id", "name", "email", "created_at"}}
+		for _, u := range users {
+			// Cursor varsa ilk iki argüman (created_at, id)
+			if len(args) >= 2 {
+				after, _ := args[0].(time.Time)
+				id, _ := args[1].(string)
+				if u.CreatedAt.After(after) || (u.CreatedAt.Equal(after) && u.ID.String() >= id) {
+					continue
+				}
+			}
+			if len(result.Rows) < limit {
+				result.Rows = append(result.Rows, []driver.Value{u.ID.String(), u.Name, u.Email, u.CreatedAt})
+			}
+		}
+		return result
+	})
+
+	return users
+}
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	user := models.User{
+		ID:        uuid.New(),
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 123456789, time.FixedZone("TRT", 3*60*60)),
+	}
+
+	decoded, err := decodeUserCursor(encodeUserCursor(user))
+	if err != nil {
+		t.Fatalf("decodeUserCursor: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(user.CreatedAt) || decoded.ID != user.ID {
+		t.Errorf("decoded = %+v, want created_at %v and id %v", decoded, user.CreatedAt, user.ID)
+	}
+}
+
+func TestGetUsersRejectsBadCursor(t *testing.T) {
+	keysetUsersDB(t, 0)
+	app := traceApp()
+	app.Get("/users", GetUsers)
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	cursors := map[string]string{
+		"not base64":   "%%%",
+		"no separator": encode("2026-01-01T00:00:00Z"),
+		"bad time":     encode("yesterday|" + uuid.NewString()),
+		"bad id":       encode("2026-01-01T00:00:00Z|not-a-uuid"),
+	}
+
+	for name, cursor := range cursors {
+		t.Run(name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?cursor="+url.QueryEscape(cursor), nil))
+			if err != nil {
+				t.Fatalf("GET /users: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", resp.StatusCode)
+			}
+			if body := decodeJSON(t, resp); body["code"] != "invalid_cursor" {
+				t.Errorf("code = %v, want invalid_cursor", body["code"])
+			}
+		})
+	}
+}
+
+func TestGetUsersKeysetPages(t *testing.T) {
+	tests := []struct {
+		total, limit int
+		wantPages    []int
+	}{
+		// Son sayfa tam dolu: fazladan boş bir sayfa istenmemeli
+		{total: 6, limit: 3, wantPages: []int{3, 3}},
+		{total: 6, limit: 4, wantPages: []int{4, 2}},
+		{total: 2, limit: 5, wantPages: []int{2}},
+		{total: 0, limit: 5, wantPages: []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d users limit %d", tt.total, tt.limit), func(t *testing.T) {
+			users := keysetUsersDB(t, tt.total)
+			app := traceApp()
+			app.Get("/users", GetUsers)
+
+			var seen []string
+			cursor := ""
+			for page, wantLen := range tt.wantPages {
+				query := url.Values{"cursor": {cursor}, "limit": {strconv.Itoa(tt.limit)}}
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?"+query.Encode(), nil))
+				if err != nil {
+					t.Fatalf("GET /users: %v", err)
+				}
+				body := decodeJSON(t, resp)
+				if resp.StatusCode != fiber.StatusOK {
+					t.Fatalf("page %d status = %d: %v", page+1, resp.StatusCode, body)
+				}
+
+				list, _ := body["users"].([]interface{})
+				if len(list) != wantLen {
+					t.Fatalf("page %d has %d users, want %d", page+1, len(list), wantLen)
+				}
+				for _, u := range list {
+					seen = append(seen, u.(map[string]interface{})["id"].(string))
+				}
+
+				last := page == len(tt.wantPages)-1
+				if hasMore := body["has_more"] == true; hasMore == last {
+					t.Errorf("page %d has_more = %v, want %v", page+1, hasMore, !last)
+				}
+				cursor, _ = body["next_cursor"].(string)
+				if last && cursor != "" {
+					t.Errorf("last page next_cursor = %q, want empty", cursor)
+				}
+			}
+
+			want := make([]string, len(users))
+			for i, u := range users {
+				want[i] = u.ID.String()
+			}
+			if fmt.Sprint(seen) != fmt.Sprint(want) {
+				t.Errorf("pages returned %v, want %v", seen, want)
+			}
+		})
+	}
+}
+
+// withTrigramSearch - pg_trgm'in kurulu olup olmadığını taklit eder; test bitince kapatılır
+func withTrigramSearch(t *testing.T, db *dbtest.DB, enabled bool) {
+	t.Helper()
+
+	disable := func() {
+		db.Fail("pg_trgm", errors.New("extension \"pg_trgm\" is not available"))
+		database.EnableTrigramSearch()
+	}
+	if enabled {
+		if err := database.EnableTrigramSearch(); err != nil {
+			t.Fatalf("EnableTrigramSearch: %v", err)
+		}
+	} else {
+		disable()
+	}
+	t.Cleanup(disable)
+}
+
+func TestGetUsersSearch(t *testing.T) {
+	tests := []struct {
+		name        string
+		trigram     bool
+		query       string
+		wantFuzzy   bool
+		wantOrderBy string
+	}{
+		{"ranked by similarity", true, "search=jo", true, "ORDER BY GREATEST(similarity(name, $"},
+		{"explicit sort wins over ranking", true, "search=jo&sort=name&order=asc", true, `ORDER BY "name","id"`},
+		{"without pg_trgm", false, "search=jo", false, `ORDER BY "created_at" DESC,"id" DESC`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			withTrigramSearch(t, db, tt.trigram)
+			app := traceApp()
+			app.Get("/users", GetUsers)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("GET /users: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d: %v", resp.StatusCode, decodeJSON(t, resp))
+			}
+
+			var list *dbtest.Statement
+			for _, stmt := range db.Statements(`SELECT * FROM "users"`) {
+				list = &stmt
+			}
+			if list == nil {
+				t.Fatal("users were not queried")
+			}
+
+			if !strings.Contains(list.SQL, "name ILIKE") || !strings.Contains(list.SQL, "email ILIKE") {
+				t.Errorf("search does not match name and email substrings: %s", list.SQL)
+			}
+			if fuzzy := strings.Contains(list.SQL, "name % $"); fuzzy != tt.wantFuzzy {
+				t.Errorf("trigram match = %v, want %v: %s", fuzzy, tt.wantFuzzy, list.SQL)
+			}
+			if !strings.Contains(list.SQL, tt.wantOrderBy) {
+				t.Errorf("query is not ordered by %q: %s", tt.wantOrderBy, list.SQL)
+			}
+			if fmt.Sprint(list.Args[:2]) != "[%jo% %jo%]" {
+				t.Errorf("args = %v, want the search pattern first", list.Args)
+			}
+		})
+	}
+}
+
+func TestGetUsersSort(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantOrderBy string
+	}{
+		{"", `ORDER BY "created_at" DESC,"id" DESC`},
+		{"sort=name", `ORDER BY "name" DESC,"id" DESC`},
+		{"sort=email&order=asc", `ORDER BY "email","id"`},
+		{"sort=updated_at&order=DESC", `ORDER BY "updated_at" DESC,"id" DESC`},
+		{"order=asc", `ORDER BY "created_at","id"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			db := dbtest.Open(t)
+			app := traceApp()
+			app.Get("/users", GetUsers)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("GET /users: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d: %v", resp.StatusCode, decodeJSON(t, resp))
+			}
+
+			list := db.Statements(`SELECT * FROM "users"`)
+			if len(list) == 0 {
+				t.Fatal("users were not queried")
+			}
+			if sql := list[len(list)-1].SQL; !strings.Contains(sql, tt.wantOrderBy) {
+				t.Errorf("query is not ordered by %q: %s", tt.wantOrderBy, sql)
+			}
+		})
+	}
+}
+
+func TestGetUsersRejectsInvalidSort(t *testing.T) {
+	queries := []string{
+		"sort=password",
+		"sort=name%3BDROP+TABLE+users",
+		"sort=name&order=sideways",
+		"sort=name&cursor=",
+		"order=asc&cursor=",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			db := dbtest.Open(t)
+			app := traceApp()
+			app.Get("/users", GetUsers)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?"+query, nil))
+			if err != nil {
+				t.Fatalf("GET /users: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", resp.StatusCode)
+			}
+			if body := decodeJSON(t, resp); body["code"] != "invalid_sort" {
+				t.Errorf("code = %v, want invalid_sort", body["code"])
+			}
+			if stmts := db.Statements(`FROM "users"`); len(stmts) != 0 {
+				t.Errorf("users queried with an invalid sort: %s", stmts[0].SQL)
+			}
+		})
+	}
+}
+
+func TestGetUsersInvalidSortListsAllowedValues(t *testing.T) {
+	dbtest.Open(t)
+	app := traceApp()
+	app.Get("/users", GetUsers)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users?sort=password", nil))
+	if err != nil {
+		t.Fatalf("GET /users: %v", err)
+	}
+	body := decodeJSON(t, resp)
+	if fmt.Sprint(body["allowed_sort"]) != fmt.Sprint(userSortColumns) {
+		t.Errorf("allowed_sort = %v, want %v", body["allowed_sort"], userSortColumns)
+	}
+	if fmt.Sprint(body["allowed_order"]) != "[asc desc]" {
+		t.Errorf("allowed_order = %v, want [asc desc]", body["allowed_order"])
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fiber-app/pkg/config"
+	"fiber-app/pkg/i18n"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fieldLimits - Alan uzunluk limitleri, SetFieldLimits ile config'ten set edilir
+var fieldLimits = config.ColumnLimits
+
+// SetFieldLimits - Alan uzunluk limitlerini set eder; kolon boyutunu aşan değerler kolon boyutuna indirilir
+func SetFieldLimits(limits config.FieldLimits) {
+	fieldLimits = limits.Clamp(config.ColumnLimits)
+}
+
+// fieldError - Limit aşımı yapan alan bilgisi
+type fieldError struct {
+	Field     string
+	MaxLength int
+}
+
+func (fe *fieldError) Error() string {
+	return fe.Message(i18n.DefaultLanguage)
+}
+
+// Message - Hatanın verilen dildeki mesajı
+func (fe *fieldError) Message(lang string) string {
+	return i18n.Message(lang, i18n.CodeFieldTooLong, fe.Field, fe.MaxLength)
+}
+
+// checkMaxLength - Değer (karakter sayısı olarak) limiti aşıyorsa fieldError döner
+func checkMaxLength(field, value string, maxLength int) *fieldError {
+	if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+		return &fieldError{Field: field, MaxLength: maxLength}
+	}
+	return nil
+}
+
+// firstFieldError - Sıradaki ilk limit aşımını döner
+func firstFieldError(errs ...*fieldError) *fieldError {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldErrorResponse - 422 ile limit aşımı yapan alanı döner
+func fieldErrorResponse(c *fiber.Ctx, fe *fieldError) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"error":      i18n.T(c, i18n.CodeFieldTooLong, fe.Field, fe.MaxLength),
+		"code":       i18n.CodeFieldTooLong,
+		"field":      fe.Field,
+		"max_length": fe.MaxLength,
+		"trace_id":   getTraceID(c),
+	})
+}
+
+// userFieldError - User alanlarının uzunluk kontrolü
+func userFieldError(name, email string) *fieldError {
+	return firstFieldError(
+		checkMaxLength("name", name, fieldLimits.UserName),
+		checkMaxLength("email", email, fieldLimits.UserEmail),
+	)
+}
+
+// roleFieldError - Role alanlarının uzunluk kontrolü
+func roleFieldError(name, description string) *fieldError {
+	return firstFieldError(
+		checkMaxLength("name", name, fieldLimits.RoleName),
+		checkMaxLength("description", description, fieldLimits.RoleDescription),
+	)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/config"
+	"fiber-app/pkg/database/dbtest"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/schema"
+)
+
+// withFieldLimits - Test süresince limitleri değiştirir
+func withFieldLimits(t *testing.T, limits config.FieldLimits) {
+	t.Helper()
+	previous := fieldLimits
+	SetFieldLimits(limits)
+	t.Cleanup(func() { fieldLimits = previous })
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) (int, fiber.Map) {
+	t.Helper()
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(fiber.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	return resp.StatusCode, decodeJSON(t, resp)
+}
+
+func TestColumnLimitsMatchModels(t *testing.T) {
+	columnSize := func(model interface{}, field string) int {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("parse schema: %v", err)
+		}
+		return s.LookUpField(field).Size
+	}
+
+	tests := []struct {
+		name  string
+		model interface{}
+		field string
+		limit int
+	}{
+		{"user name", &models.User{}, "Name", config.ColumnLimits.UserName},
+		{"user email", &models.User{}, "Email", config.ColumnLimits.UserEmail},
+		{"role name", &models.Role{}, "Name", config.ColumnLimits.RoleName},
+		{"role description", &models.Role{}, "Description", config.ColumnLimits.RoleDescription},
+	}
+	for _, tt := range tests {
+		if size := columnSize(tt.model, tt.field); size != tt.limit {
+			t.Errorf("%s column size = %d, ColumnLimits = %d", tt.name, size, tt.limit)
+		}
+	}
+}
+
+func TestSetFieldLimitsClampsToColumns(t *testing.T) {
+	withFieldLimits(t, config.FieldLimits{UserName: 1000, UserEmail: 1000, RoleName: 1000, RoleDescription: 1000})
+
+	if fieldLimits != config.ColumnLimits {
+		t.Errorf("fieldLimits = %+v, want %+v", fieldLimits, config.ColumnLimits)
+	}
+}
+
+func TestCreateUserFieldLimits(t *testing.T) {
+	db := dbtest.Open(t)
+	db.Rows(`FROM "roles"`, []string{"id", "name"}, []driver.Value{importRoleID.String(), "user"})
+	// Config'te daha büyük değer verilse bile kolon boyutu geçerlidir
+	withFieldLimits(t, config.FieldLimits{UserName: 500, UserEmail: 500})
+
+	app := traceApp()
+	app.Post("/users", CreateUser)
+
+	email := func(length int) string {
+		return strings.Repeat("a", length-len("@example.com")) + "@example.com"
+	}
+
+	tests := []struct {
+		name       string
+		userName   string
+		email      string
+		wantStatus int
+		wantField  string
+	}{
+		{"name at limit", strings.Repeat("ç", 100), "a@example.com", fiber.StatusCreated, ""},
+		{"name over limit", strings.Repeat("ç", 101), "a@example.com", fiber.StatusUnprocessableEntity, "name"},
+		{"email at limit", "Test", email(255), fiber.StatusCreated, ""},
+		{"email over limit", "Test", email(256), fiber.StatusUnprocessableEntity, "email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postJSON(t, app, "/users", fiber.Map{"name": tt.userName, "email": tt.email, "role_id": importRoleID})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantField != "" && (body["field"] != tt.wantField || body["code"] != "field_too_long") {
+				t.Errorf("body = %v, want field_too_long for %s", body, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestCreateRoleFieldLimits(t *testing.T) {
+	dbtest.Open(t)
+	withFieldLimits(t, config.FieldLimits{RoleName: 500, RoleDescription: 5000})
+
+	app := traceApp()
+	app.Post("/roles", CreateRole)
+
+	tests := []struct {
+		name        string
+		roleName    string
+		description string
+		wantStatus  int
+		wantField   string
+	}{
+		{"name at limit", strings.Repeat("r", 50), "", fiber.StatusCreated, ""},
+		{"name over limit", strings.Repeat("r", 51), "", fiber.StatusUnprocessableEntity, "name"},
+		{"description at limit", "editor", strings.Repeat("d", 500), fiber.StatusCreated, ""},
+		{"description over limit", "editor", strings.Repeat("d", 501), fiber.StatusUnprocessableEntity, "description"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postJSON(t, app, "/roles", fiber.Map{"name": tt.roleName, "description": tt.description})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantField != "" && (body["field"] != tt.wantField || body["code"] != "field_too_long") {
+				t.Errorf("body = %v, want field_too_long for %s", body, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fiber-app/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKS - Uygulamanın imzaladığı token'ları doğrulamak için public key'ler
+// @Summary JSON Web Key Set
+// @Description Uygulamanın imzaladığı JWT'leri doğrulamak için kullanılan public key'ler (JWK formatında)
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} services.JWKSet
+// @Failure 503 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *fiber.Ctx) error {
+	if authService == nil {
+		return errorResponse(c, fiber.StatusServiceUnavailable, i18n.CodeAuthNotConfigured)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(authService.JWKS())
+}
+
+// OpenIDConfiguration - Issuer ve JWKS adresini yayınlar
+// @Summary OpenID configuration
+// @Description Uygulamanın token issuer bilgisi ve JWKS adresi
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /.well-known/openid-configuration [get]
+func OpenIDConfiguration(c *fiber.Ctx) error {
+	if authService == nil {
+		return errorResponse(c, fiber.StatusServiceUnavailable, i18n.CodeAuthNotConfigured)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{
+		"issuer":                                authService.Issuer(),
+		"jwks_uri":                              c.BaseURL() + "/.well-known/jwks.json",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
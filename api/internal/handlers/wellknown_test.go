@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fiber-app/internal/services"
+	"fiber-app/pkg/config"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func wellKnownApp() *fiber.App {
+	app := traceApp()
+	app.Get("/.well-known/jwks.json", JWKS)
+	app.Get("/.well-known/openid-configuration", OpenIDConfiguration)
+	return app
+}
+
+// rsaKeyFromJWK - JWK'daki modulus ve exponent'ten RSA public key kurar
+func rsaKeyFromJWK(t *testing.T, jwk services.JWK) *rsa.PublicKey {
+	t.Helper()
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatalf("decode n: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		t.Fatalf("decode e: %v", err)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+}
+
+func TestJWKSVerifiesIssuedToken(t *testing.T) {
+	idp := newMockIdP(t)
+	as := setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{Audience: "partner-api"})
+
+	resp, err := wellKnownApp().Test(httptest.NewRequest(fiber.MethodGet, "/.well-known/jwks.json", nil))
+	if err != nil {
+		t.Fatalf("GET jwks: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if cc := resp.Header.Get(fiber.HeaderCacheControl); cc == "" {
+		t.Error("JWKS response should be cacheable")
+	}
+
+	var set services.JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		t.Fatalf("decode jwks: %v", err)
+	}
+	keys := make(map[string]services.JWK, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || key.Alg != "RS256" || key.Use != "sig" {
+			t.Errorf("key %s = %+v, want an RS256 signing key", key.Kid, key)
+		}
+		keys[key.Kid] = key
+	}
+
+	token, err := as.CreateJWTToken(&services.ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+
+	// Partner servisin yapacağı gibi sadece yayınlanan key ile doğrula
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys[kid]
+		if !ok {
+			t.Fatalf("kid %q not published", kid)
+		}
+		return rsaKeyFromJWK(t, key), nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(as.Issuer()), jwt.WithAudience("partner-api"))
+	if err != nil || !parsed.Valid {
+		t.Fatalf("issued token does not verify against the JWKS endpoint: %v", err)
+	}
+}
+
+func TestOpenIDConfigurationPublishesIssuer(t *testing.T) {
+	idp := newMockIdP(t)
+	as := setupAuth(t, idp, config.ZitadelConfig{}, config.JWTConfig{})
+
+	resp, err := wellKnownApp().Test(httptest.NewRequest(fiber.MethodGet, "http://api.example.com/.well-known/openid-configuration", nil))
+	if err != nil {
+		t.Fatalf("GET openid-configuration: %v", err)
+	}
+	body := decodeJSON(t, resp)
+
+	if body["issuer"] != as.Issuer() {
+		t.Errorf("issuer = %v, want %q", body["issuer"], as.Issuer())
+	}
+	if body["jwks_uri"] != "http://api.example.com/.well-known/jwks.json" {
+		t.Errorf("jwks_uri = %v", body["jwks_uri"])
+	}
+}
+
+func TestWellKnownWithoutAuthService(t *testing.T) {
+	for _, path := range []string{"/.well-known/jwks.json", "/.well-known/openid-configuration"} {
+		resp, err := wellKnownApp().Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusServiceUnavailable {
+			t.Errorf("%s status = %d, want 503", path, resp.StatusCode)
+		}
+	}
+}
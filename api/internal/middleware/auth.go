@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fiber-app/internal/services"
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/i18n"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -23,62 +28,71 @@ func NewAuthMiddleware(authService *services.AuthService, logger *zap.Logger) *A
 // RequireAuth - Authentication gerekli
 func (am *AuthMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		traceID := getTraceID(c)
-
-		// Authorization header'ını kontrol et
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			am.logger.Warn("Missing authorization header",
-				zap.String("trace_id", traceID),
-			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":    "Authorization header gerekli",
-				"trace_id": traceID,
-			})
+		if claims, err := am.authenticate(c); claims == nil {
+			return err
 		}
 
-		// Bearer token formatını kontrol et
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			am.logger.Warn("Invalid authorization header format",
-				zap.String("trace_id", traceID),
-			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":    "Geçersiz authorization header formatı",
-				"trace_id": traceID,
-			})
-		}
+		return c.Next()
+	}
+}
+
+// authenticate - Token'ı doğrular ve kullanıcı bilgilerini context'e ekler
+// c.Next() çağırmaz; claims nil dönerse hata yanıtı yazılmıştır ve çağıran err'i döndürüp zinciri kesmelidir
+func (am *AuthMiddleware) authenticate(c *fiber.Ctx) (*services.TokenClaims, error) {
+	traceID := getTraceID(c)
 
-		token := tokenParts[1]
+	// Token'ı Authorization header'ından, yoksa (tanımlıysa) cookie'den al
+	token, err := am.requestToken(c)
+	if err != nil {
+		am.logger.Warn("Invalid authorization header format",
+			zap.String("trace_id", traceID),
+		)
+		return nil, errorResponse(c, fiber.StatusUnauthorized, i18n.CodeInvalidAuthorizationValue)
+	}
+	if token == "" {
+		am.logger.Warn("Missing authorization header",
+			zap.String("trace_id", traceID),
+		)
+		return nil, errorResponse(c, fiber.StatusUnauthorized, i18n.CodeAuthorizationRequired)
+	}
 
-		// Token'ı validate et
-		claims, err := am.authService.ValidateToken(token)
-		if err != nil {
+	// Token'ı validate et
+	claims, err := am.authService.ValidateToken(c.UserContext(), token)
+	if err != nil {
+		var ok bool
+		if claims, ok = am.sessionFallback(c, token, err); !ok {
 			am.logger.Warn("Token validation failed",
 				zap.String("trace_id", traceID),
 				zap.Error(err),
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":    "Geçersiz token",
-				"trace_id": traceID,
-			})
+			return nil, errorResponse(c, fiber.StatusUnauthorized, i18n.CodeInvalidToken)
 		}
+	}
 
-		// User bilgilerini context'e ekle
-		c.Locals("user_id", claims.Sub)
-		c.Locals("user_name", claims.Name)
-		c.Locals("user_email", claims.Email)
-		c.Locals("user_roles", claims.Roles)
-
-		am.logger.Debug("User authenticated",
+	// Sertifikaya bağlı token'larda istemci sertifikası değişmemiş olmalı
+	if !am.certBindingValid(c, claims.CertThumbprint()) {
+		am.logger.Warn("Client certificate does not match token binding",
 			zap.String("trace_id", traceID),
 			zap.String("user_id", claims.Sub),
-			zap.String("email", claims.Email),
-			zap.Strings("roles", claims.Roles),
 		)
-
-		return c.Next()
+		return nil, errorResponse(c, fiber.StatusUnauthorized, i18n.CodeClientCertMismatch)
 	}
+
+	// User bilgilerini context'e ekle
+	c.Locals("authenticated", true)
+	c.Locals("user_id", claims.Sub)
+	c.Locals("user_name", claims.Name)
+	c.Locals("user_email", claims.Email)
+	c.Locals("user_roles", claims.Roles)
+
+	am.logger.Debug("User authenticated",
+		zap.String("trace_id", traceID),
+		zap.String("user_id", claims.Sub),
+		zap.String("email", claims.Email),
+		zap.Strings("roles", claims.Roles),
+	)
+
+	return claims, nil
 }
 
 // RequireRole - Belirli rol gerekli
@@ -86,40 +100,22 @@ func (am *AuthMiddleware) RequireRole(requiredRole string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		traceID := getTraceID(c)
 
-		// Önce authentication kontrolü
-		if err := am.RequireAuth()(c); err != nil {
+		// Önce authentication kontrolü; handler sadece rol kontrolünden sonra çalışır
+		claims, err := am.authenticate(c)
+		if claims == nil {
 			return err
 		}
 
-		// User rollerini al
-		userRoles, ok := c.Locals("user_roles").([]string)
-		if !ok {
-			am.logger.Error("Failed to get user roles from context",
-				zap.String("trace_id", traceID),
-			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":    "Rol bilgisi alınamadı",
-				"trace_id": traceID,
-			})
-		}
-
 		// Gerekli rolü kontrol et
-		hasRole := false
-		for _, role := range userRoles {
-			if role == requiredRole {
-				hasRole = true
-				break
-			}
-		}
-
-		if !hasRole {
+		if len(missingRoles(claims.Roles, []string{requiredRole})) > 0 {
 			am.logger.Warn("Insufficient permissions",
 				zap.String("trace_id", traceID),
 				zap.String("required_role", requiredRole),
-				zap.Strings("user_roles", userRoles),
+				zap.Strings("user_roles", claims.Roles),
 			)
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":         "Yetersiz yetki",
+				"error":         i18n.T(c, i18n.CodeInsufficientPermissions),
+				"code":          i18n.CodeInsufficientPermissions,
 				"required_role": requiredRole,
 				"trace_id":      traceID,
 			})
@@ -134,51 +130,65 @@ func (am *AuthMiddleware) RequireRole(requiredRole string) fiber.Handler {
 	}
 }
 
-// RequireAnyRole - Herhangi bir rolden birini gerekli
-func (am *AuthMiddleware) RequireAnyRole(requiredRoles []string) fiber.Handler {
+// RequireAnyRole - Verilen rollerden en az biri gerekli (örn. admin VEYA editor)
+func (am *AuthMiddleware) RequireAnyRole(requiredRoles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		traceID := getTraceID(c)
 
-		// Önce authentication kontrolü
-		if err := am.RequireAuth()(c); err != nil {
+		// Önce authentication kontrolü; handler sadece rol kontrolünden sonra çalışır
+		claims, err := am.authenticate(c)
+		if claims == nil {
 			return err
 		}
 
-		// User rollerini al
-		userRoles, ok := c.Locals("user_roles").([]string)
-		if !ok {
-			am.logger.Error("Failed to get user roles from context",
+		// Herhangi bir gerekli rolü kontrol et
+		if len(missingRoles(claims.Roles, requiredRoles)) == len(requiredRoles) {
+			am.logger.Warn("Insufficient permissions",
 				zap.String("trace_id", traceID),
+				zap.Strings("required_roles", requiredRoles),
+				zap.Strings("user_roles", claims.Roles),
 			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":    "Rol bilgisi alınamadı",
-				"trace_id": traceID,
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          i18n.T(c, i18n.CodeInsufficientPermissions),
+				"code":           i18n.CodeInsufficientPermissions,
+				"required_roles": requiredRoles,
+				"trace_id":       traceID,
 			})
 		}
 
-		// Herhangi bir gerekli rolü kontrol et
-		hasAnyRole := false
-		for _, userRole := range userRoles {
-			for _, requiredRole := range requiredRoles {
-				if userRole == requiredRole {
-					hasAnyRole = true
-					break
-				}
-			}
-			if hasAnyRole {
-				break
-			}
+		am.logger.Debug("Role check passed",
+			zap.String("trace_id", traceID),
+			zap.Strings("required_roles", requiredRoles),
+		)
+
+		return c.Next()
+	}
+}
+
+// RequireAllRoles - Verilen rollerin hepsi gerekli (örn. billing VE admin)
+func (am *AuthMiddleware) RequireAllRoles(requiredRoles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		traceID := getTraceID(c)
+
+		// Önce authentication kontrolü; handler sadece rol kontrolünden sonra çalışır
+		claims, err := am.authenticate(c)
+		if claims == nil {
+			return err
 		}
 
-		if !hasAnyRole {
+		// Eksik rolleri bul
+		if missing := missingRoles(claims.Roles, requiredRoles); len(missing) > 0 {
 			am.logger.Warn("Insufficient permissions",
 				zap.String("trace_id", traceID),
 				zap.Strings("required_roles", requiredRoles),
-				zap.Strings("user_roles", userRoles),
+				zap.Strings("missing_roles", missing),
+				zap.Strings("user_roles", claims.Roles),
 			)
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":          "Yetersiz yetki",
+				"error":          i18n.T(c, i18n.CodeInsufficientPermissions),
+				"code":           i18n.CodeInsufficientPermissions,
 				"required_roles": requiredRoles,
+				"missing_roles":  missing,
 				"trace_id":       traceID,
 			})
 		}
@@ -192,26 +202,44 @@ func (am *AuthMiddleware) RequireAnyRole(requiredRoles []string) fiber.Handler {
 	}
 }
 
-// Optional auth - Token varsa validate et, yoksa devam et
+// missingRoles - requiredRoles içinden kullanıcıda olmayanları döner
+func missingRoles(userRoles, requiredRoles []string) []string {
+	has := make(map[string]struct{}, len(userRoles))
+	for _, role := range userRoles {
+		has[role] = struct{}{}
+	}
+
+	var missing []string
+	for _, role := range requiredRoles {
+		if _, ok := has[role]; !ok {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// OptionalAuth - Token varsa validate et, yoksa devam et
+// Token yok veya geçersizse istek reddedilmez, sadece "authenticated" false olarak işaretlenir
 func (am *AuthMiddleware) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Next()
-		}
+		c.Locals("authenticated", false)
 
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		token, err := am.requestToken(c)
+		if err != nil || token == "" {
 			return c.Next()
 		}
 
-		token := tokenParts[1]
-		claims, err := am.authService.ValidateToken(token)
-		if err != nil {
+		claims, err := am.authService.ValidateToken(c.UserContext(), token)
+		if err != nil || !am.certBindingValid(c, claims.CertThumbprint()) {
+			am.logger.Debug("Optional auth token rejected, continuing anonymously",
+				zap.String("trace_id", getTraceID(c)),
+				zap.Error(err),
+			)
 			return c.Next()
 		}
 
 		// User bilgilerini context'e ekle
+		c.Locals("authenticated", true)
 		c.Locals("user_id", claims.Sub)
 		c.Locals("user_name", claims.Name)
 		c.Locals("user_email", claims.Email)
@@ -221,6 +249,70 @@ func (am *AuthMiddleware) OptionalAuth() fiber.Handler {
 	}
 }
 
+// TokenRefreshHeader - Süresi dolmuş token session ile kabul edildiğinde client'a yenileme sinyali
+const TokenRefreshHeader = "X-Token-Refresh-Required"
+
+// sessionFallback - Sadece süresi dolmuş bir token, kullanıcının session'ı hâlâ aktifse kabul edilir (config ile açılır)
+// Token'ın exp'i grace süresini aşmamalı ve session'a login'de kaydedilen token'ın kendisi olmalı
+func (am *AuthMiddleware) sessionFallback(c *fiber.Ctx, token string, err error) (*services.TokenClaims, bool) {
+	if !am.authService.ExpiredTokenSessionFallback() {
+		return nil, false
+	}
+
+	var validationErr *services.TokenValidationError
+	if !errors.As(err, &validationErr) || validationErr.ExpiredClaims == nil {
+		return nil, false
+	}
+
+	claims := validationErr.ExpiredClaims
+	if claims.ExpiresAt == nil || time.Since(claims.ExpiresAt.Time) > am.authService.ExpiredTokenGrace() {
+		return nil, false
+	}
+
+	var session struct {
+		TokenHash string `json:"token_hash"`
+	}
+	if err := cache.Get("session:"+claims.Sub, &session); err != nil || session.TokenHash == "" {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(session.TokenHash), []byte(services.TokenHash(token))) != 1 {
+		am.logger.Warn("Expired token does not belong to the active session",
+			zap.String("trace_id", getTraceID(c)),
+			zap.String("user_id", claims.Sub),
+		)
+		return nil, false
+	}
+
+	am.logger.Info("Expired token accepted with active session",
+		zap.String("trace_id", getTraceID(c)),
+		zap.String("user_id", claims.Sub),
+	)
+	c.Set(TokenRefreshHeader, "true")
+
+	return claims, true
+}
+
+// errInvalidAuthorizationHeader - Authorization header "Bearer <token>" formatında değil
+var errInvalidAuthorizationHeader = errors.New("invalid authorization header format")
+
+// requestToken - İstekteki access token'ı döner
+// Authorization header varsa her zaman o kullanılır (hatalıysa cookie'ye düşülmez), yoksa yapılandırılmış cookie okunur
+func (am *AuthMiddleware) requestToken(c *fiber.Ctx) (string, error) {
+	if authHeader := c.Get(fiber.HeaderAuthorization); authHeader != "" {
+		tokenParts := strings.Split(authHeader, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			return "", errInvalidAuthorizationHeader
+		}
+		return tokenParts[1], nil
+	}
+
+	if cookieName := am.authService.AccessTokenCookie(); cookieName != "" {
+		return c.Cookies(cookieName), nil
+	}
+
+	return "", nil
+}
+
 // getTraceID - Context'ten trace_id'yi alır
 func getTraceID(c *fiber.Ctx) string {
 	if traceID := c.Locals("trace_id"); traceID != nil {
@@ -228,3 +320,12 @@ func getTraceID(c *fiber.Ctx) string {
 	}
 	return "unknown"
 }
+
+// errorResponse - İsteğin diline göre lokalize edilmiş hata mesajı ve kodu ile JSON hata döner
+func errorResponse(c *fiber.Ctx, status int, code i18n.Code) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error":    i18n.T(c, code),
+		"code":     code,
+		"trace_id": getTraceID(c),
+	})
+}
@@ -0,0 +1,390 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fiber-app/internal/services"
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// newTestAuthService - Geçici signing key ile çalışan AuthService
+func newTestAuthService(t *testing.T, jwtCfg config.JWTConfig) *services.AuthService {
+	t.Helper()
+
+	if jwtCfg.Issuer == "" {
+		jwtCfg.Issuer = "fiber-app-test"
+	}
+	if jwtCfg.TokenTTL == 0 {
+		jwtCfg.TokenTTL = time.Hour
+	}
+
+	as, err := services.NewAuthService(&config.ZitadelConfig{}, &jwtCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	return as
+}
+
+// issueToken - Verilen rollere sahip kullanıcı için uygulama token'ı üretir
+func issueToken(t *testing.T, as *services.AuthService, roles ...string) string {
+	t.Helper()
+
+	token, err := as.CreateJWTToken(&services.ZitadelUserInfo{
+		Sub:   "user-1",
+		Name:  "Test User",
+		Email: "test@example.com",
+		Roles: roles,
+	}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+	return token
+}
+
+// protectedApp - guard arkasında, çağrılma sayısını tutan bir handler
+func protectedApp(guard fiber.Handler, calls *int) *fiber.App {
+	app := fiber.New()
+	app.Get("/protected", guard, func(c *fiber.Ctx) error {
+		*calls++
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, token string) (*http.Response, fiber.Map) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+
+	var body fiber.Map
+	if resp.Header.Get(fiber.HeaderContentType) == fiber.MIMEApplicationJSON {
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+	}
+	return resp, body
+}
+
+func TestRoleMiddlewares(t *testing.T) {
+	as := newTestAuthService(t, config.JWTConfig{})
+	am := NewAuthMiddleware(as, zap.NewNop())
+
+	tests := []struct {
+		name       string
+		guard      fiber.Handler
+		token      string
+		wantStatus int
+		wantCalls  int
+		wantCode   string
+	}{
+		{"require role without token", am.RequireRole("admin"), "", fiber.StatusUnauthorized, 0, "authorization_required"},
+		{"require role with invalid token", am.RequireRole("admin"), "not-a-jwt", fiber.StatusUnauthorized, 0, "invalid_token"},
+		{"require role missing role", am.RequireRole("admin"), issueToken(t, as, "user"), fiber.StatusForbidden, 0, "insufficient_permissions"},
+		{"require role granted", am.RequireRole("admin"), issueToken(t, as, "user", "admin"), fiber.StatusOK, 1, ""},
+
+		{"any role without token", am.RequireAnyRole("admin", "editor"), "", fiber.StatusUnauthorized, 0, "authorization_required"},
+		{"any role none matching", am.RequireAnyRole("admin", "editor"), issueToken(t, as, "user"), fiber.StatusForbidden, 0, "insufficient_permissions"},
+		{"any role one matching", am.RequireAnyRole("admin", "editor"), issueToken(t, as, "editor"), fiber.StatusOK, 1, ""},
+
+		{"all roles without token", am.RequireAllRoles("billing", "admin"), "", fiber.StatusUnauthorized, 0, "authorization_required"},
+		{"all roles one missing", am.RequireAllRoles("billing", "admin"), issueToken(t, as, "admin"), fiber.StatusForbidden, 0, "insufficient_permissions"},
+		{"all roles granted", am.RequireAllRoles("billing", "admin"), issueToken(t, as, "admin", "billing"), fiber.StatusOK, 1, ""},
+
+		{"require auth without token", am.RequireAuth(), "", fiber.StatusUnauthorized, 0, "authorization_required"},
+		{"require auth valid token", am.RequireAuth(), issueToken(t, as), fiber.StatusOK, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			resp, body := doRequest(t, protectedApp(tt.guard, &calls), tt.token)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			// Handler rol kontrolünden önce ya da iki kez çalışmamalı
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantCode != "" && body["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRequireAllRolesListsMissingRoles(t *testing.T) {
+	as := newTestAuthService(t, config.JWTConfig{})
+	am := NewAuthMiddleware(as, zap.NewNop())
+
+	calls := 0
+	app := protectedApp(am.RequireAllRoles("billing", "admin", "auditor"), &calls)
+	resp, body := doRequest(t, app, issueToken(t, as, "admin"))
+
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("status = %d, want 403", resp.StatusCode)
+	}
+
+	missing, _ := body["missing_roles"].([]interface{})
+	if len(missing) != 2 || missing[0] != "billing" || missing[1] != "auditor" {
+		t.Errorf("missing_roles = %v, want [billing auditor]", body["missing_roles"])
+	}
+	if _, ok := body["trace_id"]; !ok {
+		t.Error("trace_id missing from 403 response")
+	}
+}
+
+func TestExpiredTokenSessionFallback(t *testing.T) {
+	cachetest.Start(t)
+
+	jwtCfg := &config.JWTConfig{
+		Issuer:                      "fiber-app-test",
+		ExpiredTokenSessionFallback: true,
+		ExpiredTokenGrace:           15 * time.Minute,
+	}
+	as, err := services.NewAuthService(&config.ZitadelConfig{}, jwtCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	am := NewAuthMiddleware(as, zap.NewNop())
+
+	// expiredToken - exp'i verilen süre kadar geçmişte olan token
+	expiredToken := func(ago time.Duration) string {
+		jwtCfg.TokenTTL = -ago
+		defer func() { jwtCfg.TokenTTL = time.Hour }()
+		return issueToken(t, as)
+	}
+	startSession := func(token string) {
+		t.Helper()
+		if err := cache.Set("session:user-1", fiber.Map{"user_id": "user-1", "token_hash": services.TokenHash(token)}, time.Hour); err != nil {
+			t.Fatalf("store session: %v", err)
+		}
+	}
+
+	insideGrace := expiredToken(5 * time.Minute)
+	pastGrace := expiredToken(30 * time.Minute)
+	other := expiredToken(5 * time.Minute)
+
+	tests := []struct {
+		name        string
+		token       string
+		session     string
+		wantStatus  int
+		wantRefresh bool
+	}{
+		{"inside grace with matching session", insideGrace, insideGrace, fiber.StatusOK, true},
+		{"past grace", pastGrace, pastGrace, fiber.StatusUnauthorized, false},
+		{"no session", insideGrace, "", fiber.StatusUnauthorized, false},
+		{"session issued for another token", insideGrace, other, fiber.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.Delete("session:user-1")
+			if tt.session != "" {
+				startSession(tt.session)
+			}
+
+			calls := 0
+			resp, _ := doRequest(t, protectedApp(am.RequireAuth(), &calls), tt.token)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(TokenRefreshHeader) == "true"; got != tt.wantRefresh {
+				t.Errorf("%s set = %v, want %v", TokenRefreshHeader, got, tt.wantRefresh)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		jwtCfg.ExpiredTokenSessionFallback = false
+		defer func() { jwtCfg.ExpiredTokenSessionFallback = true }()
+		startSession(insideGrace)
+
+		calls := 0
+		if resp, _ := doRequest(t, protectedApp(am.RequireAuth(), &calls), insideGrace); resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusUnauthorized)
+		}
+	})
+}
+
+func TestAccessTokenCookie(t *testing.T) {
+	as := newTestAuthService(t, config.JWTConfig{AccessTokenCookie: "access_token"})
+	valid := issueToken(t, as)
+
+	tests := []struct {
+		name       string
+		header     string
+		cookie     string
+		wantStatus int
+	}{
+		{"cookie only", "", valid, fiber.StatusOK},
+		{"invalid cookie", "", "not-a-token", fiber.StatusUnauthorized},
+		{"no token", "", "", fiber.StatusUnauthorized},
+		// Header varsa cookie'ye bakılmaz
+		{"header wins over invalid cookie", "Bearer " + valid, "not-a-token", fiber.StatusOK},
+		{"invalid header does not fall back to cookie", "Bearer not-a-token", valid, fiber.StatusUnauthorized},
+		{"malformed header does not fall back to cookie", "Token " + valid, valid, fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			app := protectedApp(NewAuthMiddleware(as, zap.NewNop()).RequireAuth(), &calls)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "access_token", Value: tt.cookie})
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("cookie not configured", func(t *testing.T) {
+		plain := newTestAuthService(t, config.JWTConfig{})
+		var calls int
+		app := protectedApp(NewAuthMiddleware(plain, zap.NewNop()).RequireAuth(), &calls)
+
+		req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: issueToken(t, plain)})
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("status = %d, want 401 when no cookie name is configured", resp.StatusCode)
+		}
+	})
+}
+
+// whoAmIApp - guard'ın bıraktığı authenticated ve user_id local'lerini dönen uygulama
+func whoAmIApp(guard fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Get("/protected", guard, func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"authenticated": c.Locals("authenticated"),
+			"user_id":       c.Locals("user_id"),
+		})
+	})
+	return app
+}
+
+func TestOptionalAuth(t *testing.T) {
+	as := newTestAuthService(t, config.JWTConfig{})
+	other := newTestAuthService(t, config.JWTConfig{})
+	am := NewAuthMiddleware(as, zap.NewNop())
+	app := whoAmIApp(am.OptionalAuth())
+
+	tests := []struct {
+		name       string
+		header     string
+		wantAuthed bool
+	}{
+		{"no token", "", false},
+		{"valid token", "Bearer " + issueToken(t, as), true},
+		{"malformed header", "Token " + issueToken(t, as), false},
+		{"garbage token", "Bearer not.a.jwt", false},
+		{"token signed by another key", "Bearer " + issueToken(t, other), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 for optional auth", resp.StatusCode)
+			}
+
+			var body fiber.Map
+			json.NewDecoder(resp.Body).Decode(&body)
+			if body["authenticated"] != tt.wantAuthed {
+				t.Errorf("authenticated = %v, want %v", body["authenticated"], tt.wantAuthed)
+			}
+			if tt.wantAuthed && body["user_id"] != "user-1" {
+				t.Errorf("user_id = %v, want user-1", body["user_id"])
+			}
+			if !tt.wantAuthed && body["user_id"] != nil {
+				t.Errorf("user_id = %v set for an anonymous request", body["user_id"])
+			}
+		})
+	}
+}
+
+func TestOptionalAuthCertBoundToken(t *testing.T) {
+	as, err := services.NewAuthService(&config.ZitadelConfig{ClientCertHeader: clientCertHeader}, &config.JWTConfig{
+		Issuer:   "fiber-app-test",
+		TokenTTL: time.Hour,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	app := whoAmIApp(NewAuthMiddleware(as, zap.NewNop()).OptionalAuth())
+
+	cert := selfSignedCert(t, "client")
+	token, err := as.CreateJWTToken(&services.ZitadelUserInfo{Sub: "user-1"}, certThumbprint(cert))
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		cert       string
+		wantAuthed bool
+	}{
+		{"matching cert", forwardedCert(cert), true},
+		{"no cert", "", false},
+		{"different cert", forwardedCert(selfSignedCert(t, "other")), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := certRequest(t, app, "/protected", token, tc.cert)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", status)
+			}
+			if body["authenticated"] != tc.wantAuthed {
+				t.Errorf("authenticated = %v, want %v", body["authenticated"], tc.wantAuthed)
+			}
+		})
+	}
+}
+
+func TestRequireAuthMarksAuthenticated(t *testing.T) {
+	as := newTestAuthService(t, config.JWTConfig{})
+	app := whoAmIApp(NewAuthMiddleware(as, zap.NewNop()).RequireAuth())
+
+	resp, body := doRequest(t, app, issueToken(t, as))
+	if resp.StatusCode != fiber.StatusOK || body["authenticated"] != true {
+		t.Errorf("status = %d, authenticated = %v, want 200 and true", resp.StatusCode, body["authenticated"])
+	}
+}
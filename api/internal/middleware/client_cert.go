@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientCertFingerprint - İstemci sertifikasının SHA-256 (x5t#S256) parmak izini döner
+// Önce TLS bağlantısına, yoksa (tanımlıysa) proxy'nin forward ettiği header'a bakar
+func ClientCertFingerprint(c *fiber.Ctx, forwardedHeader string) string {
+	if state := c.Context().TLSConnectionState(); state != nil && len(state.PeerCertificates) > 0 {
+		return certThumbprint(state.PeerCertificates[0].Raw)
+	}
+
+	if forwardedHeader == "" {
+		return ""
+	}
+
+	headerValue := c.Get(forwardedHeader)
+	if headerValue == "" {
+		return ""
+	}
+
+	// nginx $ssl_client_escaped_cert gibi URL-encoded PEM değerleri
+	// Sadece escape içeren değerler çözülür; düz base64 DER'deki '+' boşluğa çevrilmemeli
+	if strings.Contains(headerValue, "%") {
+		if unescaped, err := url.QueryUnescape(headerValue); err == nil {
+			headerValue = unescaped
+		}
+	}
+
+	if block, _ := pem.Decode([]byte(headerValue)); block != nil {
+		return certThumbprint(block.Bytes)
+	}
+
+	// PEM değilse base64 DER kabul et
+	if der, err := base64.StdEncoding.DecodeString(headerValue); err == nil {
+		return certThumbprint(der)
+	}
+
+	return ""
+}
+
+// certThumbprint - RFC 8705 x5t#S256 formatı (base64url, padding'siz)
+func certThumbprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// certBindingValid - Token bir sertifikaya bağlıysa istekteki sertifika ile eşleşiyor mu
+func (am *AuthMiddleware) certBindingValid(c *fiber.Ctx, boundThumbprint string) bool {
+	if boundThumbprint == "" {
+		return true
+	}
+
+	return ClientCertFingerprint(c, am.authService.ClientCertHeader()) == boundThumbprint
+}
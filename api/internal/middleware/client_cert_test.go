@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fiber-app/internal/services"
+	"fiber-app/pkg/config"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const clientCertHeader = "X-Client-Cert"
+
+// selfSignedCert - Test için DER formatında self-signed istemci sertifikası
+func selfSignedCert(t *testing.T, cn string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	return der
+}
+
+func pemCert(der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// forwardedCert - Proxy'nin header'a koyduğu biçim (nginx $ssl_client_escaped_cert)
+func forwardedCert(der []byte) string {
+	return url.QueryEscape(pemCert(der))
+}
+
+// certRequest - Forward edilmiş sertifika header'ı ile istek atar
+func certRequest(t *testing.T, app *fiber.App, path, token, cert string) (int, fiber.Map) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	if cert != "" {
+		req.Header.Set(clientCertHeader, cert)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	var body fiber.Map
+	if resp.Header.Get(fiber.HeaderContentType) == fiber.MIMEApplicationJSON {
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+	}
+	return resp.StatusCode, body
+}
+
+func TestClientCertFingerprintFromHeader(t *testing.T) {
+	der := selfSignedCert(t, "client")
+	want := certThumbprint(der)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   string
+	}{
+		{"url-escaped pem", clientCertHeader, url.QueryEscape(pemCert(der)), want},
+		{"base64 der", clientCertHeader, base64.StdEncoding.EncodeToString(der), want},
+		{"garbage", clientCertHeader, "not a certificate!", ""},
+		{"missing", clientCertHeader, "", ""},
+		{"header not configured", "", url.QueryEscape(pemCert(der)), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got string
+			app.Get("/", func(c *fiber.Ctx) error {
+				got = ClientCertFingerprint(c, tt.header)
+				return nil
+			})
+
+			certRequest(t, app, "/", "", tt.value)
+			if got != tt.want {
+				t.Errorf("fingerprint = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertBoundTokenRequiresSameCert(t *testing.T) {
+	as, err := services.NewAuthService(&config.ZitadelConfig{ClientCertHeader: clientCertHeader}, &config.JWTConfig{
+		Issuer:   "fiber-app-test",
+		TokenTTL: time.Hour,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	am := NewAuthMiddleware(as, zap.NewNop())
+
+	var calls int
+	app := protectedApp(am.RequireAuth(), &calls)
+
+	certA := selfSignedCert(t, "client-a")
+	certB := selfSignedCert(t, "client-b")
+
+	user := &services.ZitadelUserInfo{Sub: "user-1"}
+	bound, err := as.CreateJWTToken(user, certThumbprint(certA))
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+	unbound := issueToken(t, as)
+
+	tests := []struct {
+		name       string
+		token      string
+		cert       string
+		wantStatus int
+	}{
+		{"matching cert", bound, forwardedCert(certA), fiber.StatusOK},
+		{"different cert", bound, forwardedCert(certB), fiber.StatusUnauthorized},
+		{"no cert", bound, "", fiber.StatusUnauthorized},
+		{"unbound token without cert", unbound, "", fiber.StatusOK},
+		{"unbound token with cert", unbound, forwardedCert(certB), fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := certRequest(t, app, "/protected", tt.token, tt.cert)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %v", status, tt.wantStatus, body)
+			}
+			if status == fiber.StatusUnauthorized && body["code"] != "client_cert_mismatch" {
+				t.Errorf("code = %v, want client_cert_mismatch", body["code"])
+			}
+		})
+	}
+}
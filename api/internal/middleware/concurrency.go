@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"fiber-app/pkg/i18n"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/sync/semaphore"
+)
+
+// ConcurrencyLimit - Uygulandığı route grubunda aynı anda en fazla n isteğin işlenmesine izin verir
+// Limit doluysa istek queueTimeout kadar slot bekler (0 ise beklemez) ve slot açılmazsa 503 döner
+// n <= 0 limiti kapatır
+func ConcurrencyLimit(n int, queueTimeout time.Duration) fiber.Handler {
+	if n <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	sem := semaphore.NewWeighted(int64(n))
+
+	return func(c *fiber.Ctx) error {
+		if !acquireSlot(c.UserContext(), sem, queueTimeout) {
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return errorResponse(c, fiber.StatusServiceUnavailable, i18n.CodeServerBusy)
+		}
+		defer sem.Release(1)
+
+		return c.Next()
+	}
+}
+
+// acquireSlot - Semaphore'dan bir slot alır, gerekirse timeout süresince bekler
+func acquireSlot(ctx context.Context, sem *semaphore.Weighted, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return sem.TryAcquire(1)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return sem.Acquire(ctx, 1) == nil
+}
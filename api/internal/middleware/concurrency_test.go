@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// blockingApp - İstekleri release kapanana kadar handler içinde tutan uygulama
+func blockingApp(limit fiber.Handler) (app *fiber.App, entered chan struct{}, release chan struct{}) {
+	entered = make(chan struct{}, 16)
+	release = make(chan struct{})
+
+	app = fiber.New()
+	app.Get("/slow", limit, func(c *fiber.Ctx) error {
+		entered <- struct{}{}
+		<-release
+		return c.SendString("ok")
+	})
+	app.Get("/fast", limit, func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app, entered, release
+}
+
+// inFlight - İsteği arka planda gönderir, status kodunu kanala yazar
+func inFlight(t *testing.T, app *fiber.App, path string) <-chan int {
+	t.Helper()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Errorf("GET %s: %v", path, err)
+			status <- 0
+			return
+		}
+		status <- resp.StatusCode
+	}()
+	return status
+}
+
+func waitEntered(t *testing.T, entered <-chan struct{}, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-entered:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of %d requests reached the handler", i, n)
+		}
+	}
+}
+
+func TestConcurrencyLimitShedsExcessRequests(t *testing.T) {
+	app, entered, release := blockingApp(ConcurrencyLimit(2, 0))
+
+	first := inFlight(t, app, "/slow")
+	second := inFlight(t, app, "/slow")
+	waitEntered(t, entered, 2)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/fast", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /fast: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 while the group is full", resp.StatusCode)
+	}
+	if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Error("Retry-After header missing")
+	}
+	var body fiber.Map
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body["code"] != "server_busy" {
+		t.Errorf("code = %v, want server_busy", body["code"])
+	}
+
+	// Slotlar boşalınca yeni istekler tekrar kabul edilir
+	close(release)
+	for _, status := range []<-chan int{first, second} {
+		if got := <-status; got != fiber.StatusOK {
+			t.Errorf("in-flight request status = %d, want 200", got)
+		}
+	}
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/fast", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /fast: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status = %d after slots freed, want 200", resp.StatusCode)
+	}
+}
+
+func TestConcurrencyLimitQueuesUntilSlotFrees(t *testing.T) {
+	app, entered, release := blockingApp(ConcurrencyLimit(1, 2*time.Second))
+
+	first := inFlight(t, app, "/slow")
+	waitEntered(t, entered, 1)
+
+	queued := inFlight(t, app, "/fast")
+	select {
+	case got := <-queued:
+		t.Fatalf("queued request finished with %d before a slot freed", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if got := <-first; got != fiber.StatusOK {
+		t.Errorf("first request status = %d, want 200", got)
+	}
+	if got := <-queued; got != fiber.StatusOK {
+		t.Errorf("queued request status = %d, want 200", got)
+	}
+}
+
+func TestConcurrencyLimitQueueTimeout(t *testing.T) {
+	app, entered, release := blockingApp(ConcurrencyLimit(1, 50*time.Millisecond))
+	defer close(release)
+
+	inFlight(t, app, "/slow")
+	waitEntered(t, entered, 1)
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/fast", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /fast: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 after the queue timeout", resp.StatusCode)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("request shed after %v, want it to wait for the queue timeout", waited)
+	}
+}
+
+func TestConcurrencyLimitDisabled(t *testing.T) {
+	app, entered, release := blockingApp(ConcurrencyLimit(0, 0))
+
+	var statuses []<-chan int
+	for i := 0; i < 5; i++ {
+		statuses = append(statuses, inFlight(t, app, "/slow"))
+	}
+	waitEntered(t, entered, 5)
+
+	close(release)
+	for _, status := range statuses {
+		if got := <-status; got != fiber.StatusOK {
+			t.Errorf("status = %d, want 200", got)
+		}
+	}
+}
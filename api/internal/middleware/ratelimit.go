@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/i18n"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// rateLimitPrefix - Rate limit sayaçlarının cache key prefix'i
+const rateLimitPrefix = "rate_limit:"
+
+// RateLimit - keyFn'in döndüğü key başına window içinde en fazla limit isteğe izin verir (Redis sliding window)
+// keyFn boş dönerse istek sınırlanmaz; Redis'e ulaşılamazsa istekler engellenmez (fail open)
+func RateLimit(limit int, window time.Duration, keyFn func(*fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := keyFn(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		result, err := cache.SlidingWindowAllow(rateLimitPrefix+key, limit, window)
+		if err != nil {
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return errorResponse(c, fiber.StatusTooManyRequests, i18n.CodeRateLimited)
+		}
+
+		return c.Next()
+	}
+}
+
+// IPRateLimitKey - İstemci IP'sine göre rate limit key'i
+func IPRateLimitKey(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// UserRateLimitKey - Authenticate olmuş kullanıcıya göre rate limit key'i (anonim isteklerde boş)
+func UserRateLimitKey(c *fiber.Ctx) string {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return ""
+	}
+	return "user:" + userID
+}
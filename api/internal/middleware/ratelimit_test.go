@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fiber-app/pkg/cache/cachetest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// rateLimitedApp - X-Key header'ına göre sınırlanan uygulama
+func rateLimitedApp(limit int, window time.Duration) *fiber.App {
+	app := fiber.New()
+	app.Get("/", RateLimit(limit, window, func(c *fiber.Ctx) string {
+		return c.Get("X-Key")
+	}), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func rateLimitedRequest(t *testing.T, app *fiber.App, key string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if key != "" {
+		req.Header.Set("X-Key", key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return resp
+}
+
+func TestRateLimit(t *testing.T) {
+	redis := cachetest.Start(t)
+	app := rateLimitedApp(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		resp := rateLimitedRequest(t, app, "client-a")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, resp.StatusCode)
+		}
+		if got := resp.Header.Get("X-RateLimit-Remaining"); got != strconv.Itoa(2-i) {
+			t.Errorf("request %d remaining = %s, want %d", i+1, got, 2-i)
+		}
+		if got := resp.Header.Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("limit header = %s, want 3", got)
+		}
+		redis.FastForward(10 * time.Second)
+	}
+
+	resp := rateLimitedRequest(t, app, "client-a")
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429 over the limit", resp.StatusCode)
+	}
+	// En eski istek 30s önce yapıldı; pencereden 30s sonra çıkar
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "30" {
+		t.Errorf("Retry-After = %s, want 30", got)
+	}
+	var body fiber.Map
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body["code"] != "rate_limited" {
+		t.Errorf("code = %v, want rate_limited", body["code"])
+	}
+
+	// Başka bir key etkilenmez
+	if resp := rateLimitedRequest(t, app, "client-b"); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("other key status = %d, want 200", resp.StatusCode)
+	}
+
+	// Pencere kaydıkça en eski istek düşer ve yeni isteğe yer açılır
+	redis.FastForward(31 * time.Second)
+	if resp := rateLimitedRequest(t, app, "client-a"); resp.StatusCode != fiber.StatusOK {
+		t.Errorf("status after the window slid = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestRateLimitEmptyKeyIsNotLimited(t *testing.T) {
+	redis := cachetest.Start(t)
+	app := rateLimitedApp(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if resp := rateLimitedRequest(t, app, ""); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, resp.StatusCode)
+		}
+	}
+	if n := redis.CommandCount("EVALSHA") + redis.CommandCount("EVAL"); n != 0 {
+		t.Errorf("Redis consulted %d times for an empty key", n)
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	redis := cachetest.Start(t)
+	redis.FailCommand("EVALSHA", "")
+	redis.FailCommand("EVAL", "")
+	app := rateLimitedApp(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		resp := rateLimitedRequest(t, app, "client-a")
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d status = %d, want 200 while Redis fails", i+1, resp.StatusCode)
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") != "" {
+			t.Error("rate limit headers set without a Redis result")
+		}
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	app := fiber.New()
+	var ipKey, userKey, anonKey string
+	app.Get("/", func(c *fiber.Ctx) error {
+		ipKey = IPRateLimitKey(c)
+		anonKey = UserRateLimitKey(c)
+		c.Locals("user_id", "user-1")
+		userKey = UserRateLimitKey(c)
+		return nil
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if ipKey != "ip:0.0.0.0" {
+		t.Errorf("ip key = %q", ipKey)
+	}
+	if anonKey != "" || userKey != "user:user-1" {
+		t.Errorf("user keys = %q (anonymous), %q, want \"\" and user:user-1", anonKey, userKey)
+	}
+}
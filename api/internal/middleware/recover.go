@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fiber-app/pkg/i18n"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Recover - Handler panic'lerini yakalar, stack'i trace_id ile loglar ve JSON hata döner
+func Recover(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				traceID := getTraceID(c)
+
+				logger.Error("Handler panic",
+					zap.String("trace_id", traceID),
+					zap.String("method", c.Method()),
+					zap.String("path", c.Path()),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()),
+				)
+
+				err = errorResponse(c, fiber.StatusInternalServerError, i18n.CodeInternalError)
+			}
+		}()
+
+		return c.Next()
+	}
+}
+
+// SafeGo - Background goroutine'i panic'e karşı korumalı başlatır
+func SafeGo(logger *zap.Logger, name string, fn func()) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("Goroutine panic",
+					zap.String("goroutine", name),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()),
+				)
+			}
+		}()
+
+		fn()
+	}()
+}
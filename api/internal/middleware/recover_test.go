@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecoverReturnsJSONWithTraceID(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("trace_id", "trace-123")
+		return c.Next()
+	})
+	app.Use(Recover(zap.New(core)))
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/panic", nil)
+	req.Header.Set(fiber.HeaderAcceptLanguage, "tr")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+
+	var body fiber.Map
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("panic response is not JSON: %v", err)
+	}
+	if body["trace_id"] != "trace-123" || body["code"] != "internal_error" || body["error"] != "Sunucu hatası" {
+		t.Errorf("body = %v", body)
+	}
+
+	entries := logs.FilterMessage("Handler panic").All()
+	if len(entries) != 1 {
+		t.Fatalf("panic logs = %d, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["trace_id"] != "trace-123" || fields["path"] != "/panic" || fields["panic"] != "boom" || fields["stack"] == "" {
+		t.Errorf("panic log fields = %v", fields)
+	}
+
+	// Panic sonrası uygulama çalışmaya devam eder
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/ok", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Errorf("request after panic: status %v, err %v", resp.StatusCode, err)
+	}
+}
+
+func TestSafeGoRecoversPanic(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+
+	done := make(chan struct{})
+	SafeGo(zap.New(core), "worker", func() {
+		defer close(done)
+		panic("background boom")
+	})
+	<-done
+
+	// Log recover defer'ında, close'dan sonra yazılır
+	deadline := time.Now().Add(time.Second)
+	for logs.FilterMessage("Goroutine panic").Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	entries := logs.FilterMessage("Goroutine panic").All()
+	if len(entries) != 1 || entries[0].ContextMap()["goroutine"] != "worker" {
+		t.Errorf("goroutine panic logs = %v", entries)
+	}
+}
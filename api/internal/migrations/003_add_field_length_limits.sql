@@ -0,0 +1,8 @@
+-- Migration: Add length limits to free-text columns
+-- Up
+ALTER TABLE roles ALTER COLUMN description TYPE VARCHAR(500);
+
+-- name (roles: 50, users: 100) ve email (255) limitleri 001/002'de tanımlı
+
+-- Down (for rollback)
+-- ALTER TABLE roles ALTER COLUMN description TYPE TEXT;
@@ -0,0 +1,11 @@
+-- Migration: Enable trigram search on users
+-- Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+
+-- Down (for rollback)
+-- DROP INDEX IF EXISTS idx_users_email_trgm;
+-- DROP INDEX IF EXISTS idx_users_name_trgm;
+-- DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,20 @@
+-- Migration: Create role_histories table
+-- Up
+CREATE TABLE IF NOT EXISTS role_histories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    previous_name VARCHAR(50),
+    name VARCHAR(50),
+    previous_description VARCHAR(500),
+    description VARCHAR(500),
+    changed_fields VARCHAR(100),
+    changed_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Rol silinse de geçmişi audit için tutulur (foreign key yok)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_history_version ON role_histories(role_id, version);
+
+-- Down (for rollback)
+-- DROP TABLE IF EXISTS role_histories;
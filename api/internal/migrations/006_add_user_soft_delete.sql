@@ -0,0 +1,15 @@
+-- Migration: Add soft delete to users
+-- Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+
+-- Email sadece silinmemiş kullanıcılar arasında unique (silinen kullanıcının email'i tekrar kullanılabilir)
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+
+-- Down (for rollback)
+-- DROP INDEX IF EXISTS idx_users_email_active;
+-- CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+-- DROP INDEX IF EXISTS idx_users_deleted_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
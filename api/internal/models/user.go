@@ -10,23 +10,39 @@ import (
 // Role - Kullanıcı rolleri
 type Role struct {
 	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name        string    `json:"name" gorm:"uniqueIndex;not null"` // admin, user, moderator
-	Description string    `json:"description"`
+	Name        string    `json:"name" gorm:"size:50;uniqueIndex;not null"` // admin, user, moderator
+	Description string    `json:"description" gorm:"size:500"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// RoleHistory - Rol güncellemelerinin önceki/sonraki değerleri (audit)
+type RoleHistory struct {
+	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RoleID              uuid.UUID `json:"role_id" gorm:"type:uuid;not null;uniqueIndex:idx_role_history_version"`
+	Version             int       `json:"version" gorm:"not null;uniqueIndex:idx_role_history_version"`
+	PreviousName        string    `json:"previous_name" gorm:"size:50"`
+	Name                string    `json:"name" gorm:"size:50"`
+	PreviousDescription string    `json:"previous_description" gorm:"size:500"`
+	Description         string    `json:"description" gorm:"size:500"`
+	ChangedFields       string    `json:"changed_fields" gorm:"size:100"` // virgülle ayrılmış: name,description
+	ChangedBy           string    `json:"changed_by,omitempty" gorm:"size:255"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
 // User - Kullanıcı modeli
+// Silme işlemi soft delete'tir; email sadece silinmemiş kullanıcılar arasında unique'tir
 type User struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name      string    `json:"name" gorm:"not null"`
-	Email     string    `json:"email" gorm:"uniqueIndex;not null"`
-	Age       int       `json:"age"`
-	Active    bool      `json:"active" gorm:"default:true"`
-	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;not null"`
-	Role      Role      `json:"role" gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name      string         `json:"name" gorm:"size:100;not null"`
+	Email     string         `json:"email" gorm:"size:255;not null;uniqueIndex:idx_users_email_active,where:deleted_at IS NULL"`
+	Age       int            `json:"age"`
+	Active    bool           `json:"active" gorm:"default:true"`
+	RoleID    uuid.UUID      `json:"role_id" gorm:"type:uuid;not null"`
+	Role      Role           `json:"role" gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // BeforeCreate hook - ID oluştur
@@ -44,6 +60,13 @@ func (r *Role) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (h *RoleHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
 // CreateUserRequest - User oluşturma isteği
 type CreateUserRequest struct {
 	Name   string    `json:"name" validate:"required,min=2,max=100"`
@@ -73,3 +96,32 @@ type UpdateRoleRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=50"`
 	Description *string `json:"description,omitempty"`
 }
+
+// ImportUserRow - Toplu import'taki tek kullanıcı satırı
+type ImportUserRow struct {
+	Name   string    `json:"name"`
+	Email  string    `json:"email"`
+	Age    int       `json:"age"`
+	Active *bool     `json:"active,omitempty"`
+	RoleID uuid.UUID `json:"role_id"`
+}
+
+// BulkRowResult - Import'taki bir satırın validasyon/işlem sonucu
+type BulkRowResult struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	// Errors ile aynı sırada, dilden bağımsız hata kodları
+	Codes []string `json:"codes,omitempty"`
+}
+
+// BulkResult - Toplu import sonucu
+type BulkResult struct {
+	DryRun   bool            `json:"dry_run"`
+	Total    int             `json:"total"`
+	Valid    int             `json:"valid"`
+	Invalid  int             `json:"invalid"`
+	Imported int             `json:"imported"`
+	Rows     []BulkRowResult `json:"rows"`
+}
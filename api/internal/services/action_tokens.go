@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"fiber-app/pkg/cache"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// actionTokenType - Action token'ların JWT typ header'ı (access token'lardan ayırmak için)
+	actionTokenType = "action+jwt"
+	// minActionTokenUsedTTL - Kullanım kaydının en kısa süresi (SET'e sıfır/negatif TTL gönderilmez)
+	minActionTokenUsedTTL = time.Second
+)
+
+var (
+	// ErrNotActionToken - Token bir action token değil
+	ErrNotActionToken = errors.New("not an action token")
+	// ErrActionTokenNotAccessToken - Action token access token olarak kullanılmaya çalışıldı
+	ErrActionTokenNotAccessToken = errors.New("action token cannot be used as an access token")
+	// ErrActionMismatch - Token başka bir işlem için verilmiş
+	ErrActionMismatch = errors.New("action token was issued for a different action")
+	// ErrActionTokenUsed - Token daha önce kullanılmış
+	ErrActionTokenUsed = errors.New("action token has already been used")
+)
+
+// ActionClaims - Tek kullanımlık, tek amaçlı action token claim'leri
+type ActionClaims struct {
+	Action string `json:"action"`
+	jwt.RegisteredClaims
+}
+
+// IssueActionToken - Belirli bir kullanıcı ve işlem için kısa ömürlü, tek kullanımlık token üretir
+// (örn. imzalı download linki veya işlem onayı)
+func (as *AuthService) IssueActionToken(subject, action string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := ActionClaims{
+		Action: action,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   subject,
+			Issuer:    as.jwtConfig.Issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	if as.jwtConfig.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{as.jwtConfig.Audience}
+	}
+
+	key := as.signingKeys.current()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.kid
+	token.Header["typ"] = actionTokenType
+
+	tokenString, err := token.SignedString(key.privateKey)
+	if err != nil {
+		as.logger.Error("Failed to create action token", zap.Error(err))
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// VerifyActionToken - Action token'ı doğrular ve kullanılmış olarak işaretler
+// Token beklenen işlem için verilmemişse veya daha önce kullanılmışsa hata döner
+func (as *AuthService) VerifyActionToken(tokenString, action string) (*ActionClaims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(as.jwtConfig.Issuer),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(as.clockSkew),
+		jwt.WithExpirationRequired(),
+	}
+	if as.jwtConfig.Audience != "" {
+		opts = append(opts, jwt.WithAudience(as.jwtConfig.Audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != actionTokenType {
+			return nil, ErrNotActionToken
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		publicKey, ok := as.signingKeys.publicKey(kid)
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+		return publicKey, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid || claims.ID == "" {
+		return nil, ErrNotActionToken
+	}
+
+	if claims.Action != action {
+		return nil, ErrActionMismatch
+	}
+
+	// jti, token'ın kabul edilebileceği süre (kalan ömür + clock skew) boyunca kullanılmış olarak tutulur;
+	// leeway içindeki süresi dolmuş token'da kalan ömür negatif olabilir, bu yüzden TTL alttan sınırlanır.
+	// Redis yoksa token kabul edilmez
+	ttl := time.Until(claims.ExpiresAt.Time) + as.clockSkew
+	if ttl < minActionTokenUsedTTL {
+		ttl = minActionTokenUsedTTL
+	}
+	first, err := cache.SetNX(ActionTokenUsedPrefix+claims.ID, true, ttl)
+	if err != nil {
+		as.logger.Error("Failed to mark action token as used",
+			zap.String("action", action),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	if !first {
+		as.logger.Warn("Action token reuse rejected",
+			zap.String("action", action),
+			zap.String("subject", claims.Subject),
+		)
+		return nil, ErrActionTokenUsed
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestActionTokenIssueVerify(t *testing.T) {
+	redis := cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{Audience: "fiber-app"})
+
+	token, err := as.IssueActionToken("user-1", "download:report-42", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("IssueActionToken: %v", err)
+	}
+
+	claims, err := as.VerifyActionToken(token, "download:report-42")
+	if err != nil {
+		t.Fatalf("VerifyActionToken: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Action != "download:report-42" || claims.ID == "" {
+		t.Errorf("claims = %+v, want subject, action and jti", claims)
+	}
+
+	// Kullanım kaydı token'ın ömrü kadar tutulur
+	key := ActionTokenUsedPrefix + claims.ID
+	if ttl := redis.TTL(key); ttl <= 0 || ttl > 5*time.Minute {
+		t.Errorf("used marker ttl = %v, want the token's remaining lifetime", ttl)
+	}
+}
+
+func TestActionTokenSingleUse(t *testing.T) {
+	cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{})
+
+	token, err := as.IssueActionToken("user-1", "confirm:delete", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueActionToken: %v", err)
+	}
+
+	if _, err := as.VerifyActionToken(token, "confirm:delete"); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if _, err := as.VerifyActionToken(token, "confirm:delete"); !errors.Is(err, ErrActionTokenUsed) {
+		t.Errorf("second use err = %v, want ErrActionTokenUsed", err)
+	}
+
+	// Her token'ın kendi jti'si var
+	other, _ := as.IssueActionToken("user-1", "confirm:delete", time.Minute)
+	if _, err := as.VerifyActionToken(other, "confirm:delete"); err != nil {
+		t.Errorf("fresh token rejected: %v", err)
+	}
+}
+
+func TestActionTokenExpiredWithinClockSkew(t *testing.T) {
+	redis := cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{ClockSkew: 30 * time.Second, MaxClockSkew: time.Minute})
+
+	// exp 5s geçmişte ama 30s leeway içinde: token kabul edilir, kullanım kaydı yine de yazılmalı
+	token, err := as.IssueActionToken("user-1", "confirm:delete", -5*time.Second)
+	if err != nil {
+		t.Fatalf("IssueActionToken: %v", err)
+	}
+
+	claims, err := as.VerifyActionToken(token, "confirm:delete")
+	if err != nil {
+		t.Fatalf("VerifyActionToken within skew: %v", err)
+	}
+
+	// Kayıt, token'ın leeway ile kabul edildiği süre kadar (~25s) kalır
+	if ttl := redis.TTL(ActionTokenUsedPrefix + claims.ID); ttl < 20*time.Second || ttl > 25*time.Second {
+		t.Errorf("used marker ttl = %v, want the remaining lifetime plus clock skew", ttl)
+	}
+	if _, err := as.VerifyActionToken(token, "confirm:delete"); !errors.Is(err, ErrActionTokenUsed) {
+		t.Errorf("reuse within skew err = %v, want ErrActionTokenUsed", err)
+	}
+}
+
+func TestActionTokenRejections(t *testing.T) {
+	cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{})
+
+	accessToken, err := as.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+	expired, _ := as.IssueActionToken("user-1", "download", -time.Hour)
+	download, _ := as.IssueActionToken("user-1", "download", time.Minute)
+
+	tests := []struct {
+		name    string
+		token   string
+		action  string
+		wantErr error
+	}{
+		{"wrong action", download, "confirm:delete", ErrActionMismatch},
+		{"expired", expired, "download", jwt.ErrTokenExpired},
+		{"access token", accessToken, "download", ErrNotActionToken},
+		{"garbage", "not.a.jwt", "download", jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := as.VerifyActionToken(tt.token, tt.action)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Yanlış işlem denemesi token'ı harcamaz
+	if _, err := as.VerifyActionToken(download, "download"); err != nil {
+		t.Errorf("token rejected after a wrong-action attempt: %v", err)
+	}
+}
+
+func TestActionTokenIsNotAccessToken(t *testing.T) {
+	cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{})
+
+	token, err := as.IssueActionToken("user-1", "download", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueActionToken: %v", err)
+	}
+	if _, err := as.ValidateToken(context.Background(), token); err == nil {
+		t.Error("action token accepted as an access token")
+	}
+}
+
+func TestActionTokenRequiresRedis(t *testing.T) {
+	redis := cachetest.Start(t)
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{})
+	redis.FailCommand("SET", ActionTokenUsedPrefix)
+
+	token, err := as.IssueActionToken("user-1", "download", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueActionToken: %v", err)
+	}
+	if _, err := as.VerifyActionToken(token, "download"); err == nil {
+		t.Error("token accepted without recording its use")
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// fakeIdP - Authorization code exchange'i taklit eden token endpoint'i
+type fakeIdP struct {
+	mu          sync.Mutex
+	nonce       string
+	verifier    string
+	redirectURI string
+	exchanges   int
+	// Boşsa opaque bir access token döner
+	accessToken string
+}
+
+func newFakeIdP(t *testing.T) (*fakeIdP, *config.ZitadelConfig) {
+	t.Helper()
+
+	idp := &fakeIdP{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/v2/token" || r.FormValue("grant_type") != "authorization_code" {
+			http.NotFound(w, r)
+			return
+		}
+
+		idp.mu.Lock()
+		idp.exchanges++
+		idp.verifier = r.FormValue("code_verifier")
+		idp.redirectURI = r.FormValue("redirect_uri")
+		nonce := idp.nonce
+		accessToken := idp.accessToken
+		idp.mu.Unlock()
+		if accessToken == "" {
+			accessToken = "idp-access-token"
+		}
+
+		idToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   "user-1",
+			"nonce": nonce,
+		}).SignedString([]byte("idp-secret"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": accessToken,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return idp, &config.ZitadelConfig{
+		Domain:       srv.URL,
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/callback",
+		PKCETTL:      10 * time.Minute,
+	}
+}
+
+// authorize - Auth URL'i üretir ve IdP'nin ID token'a koyacağı nonce'u authorization isteğinden alır
+func (idp *fakeIdP) authorize(t *testing.T, as *AuthService) (state string, params url.Values) {
+	t.Helper()
+
+	authURL, state, err := as.GenerateAuthURL("")
+	if err != nil {
+		t.Fatalf("GenerateAuthURL: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+
+	params = u.Query()
+	idp.mu.Lock()
+	idp.nonce = params.Get("nonce")
+	idp.mu.Unlock()
+	return state, params
+}
+
+func TestPKCESharedAcrossInstances(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp, cfg := newFakeIdP(t)
+
+	// Login A instance'ında başlar, callback B instance'ına düşer; ortak olan sadece Redis
+	instanceA := newTestAuthService(t, cfg, &config.JWTConfig{})
+	instanceB := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	state, params := idp.authorize(t, instanceA)
+	if params.Get("code_challenge_method") != "S256" || params.Get("code_challenge") == "" {
+		t.Fatalf("auth url params = %v, want an S256 code challenge", params)
+	}
+	if ttl := redis.TTL(PKCEPrefix + state); ttl <= 0 || ttl > cfg.PKCETTL {
+		t.Errorf("pkce ttl = %v, want at most %v", ttl, cfg.PKCETTL)
+	}
+
+	if _, err := instanceB.ExchangeCodeForToken(context.Background(), state, "auth-code"); err != nil {
+		t.Fatalf("ExchangeCodeForToken on instance B: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(idp.verifier))
+	if challenge := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != params.Get("code_challenge") {
+		t.Errorf("exchanged verifier does not match the challenge sent by instance A")
+	}
+	if redis.Exists(PKCEPrefix + state) {
+		t.Error("pkce verifier left in Redis after the exchange")
+	}
+}
+
+func TestPKCEVerifierIsSingleUse(t *testing.T) {
+	cachetest.Start(t)
+	idp, cfg := newFakeIdP(t)
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	state, _ := idp.authorize(t, as)
+	if _, err := as.ExchangeCodeForToken(context.Background(), state, "auth-code"); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+	if _, err := as.ExchangeCodeForToken(context.Background(), state, "auth-code"); err == nil {
+		t.Error("second exchange with the same state succeeded")
+	}
+	if _, err := as.ExchangeCodeForToken(context.Background(), "unknown-state", "auth-code"); err == nil {
+		t.Error("exchange with an unknown state succeeded")
+	}
+	if idp.exchanges != 1 {
+		t.Errorf("token endpoint called %d times, want 1", idp.exchanges)
+	}
+}
+
+func TestNonceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		idNonce func(sent string) string
+		wantErr bool
+	}{
+		{"echoed", func(sent string) string { return sent }, false},
+		{"missing", func(string) string { return "" }, true},
+		{"different", func(string) string { return "replayed-nonce" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachetest.Start(t)
+			idp, cfg := newFakeIdP(t)
+			as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+			state, params := idp.authorize(t, as)
+			if params.Get("nonce") == "" {
+				t.Fatal("auth url has no nonce")
+			}
+			idp.nonce = tt.idNonce(params.Get("nonce"))
+
+			_, err := as.ExchangeCodeForToken(context.Background(), state, "auth-code")
+			if tt.wantErr && !errors.Is(err, ErrNonceMismatch) {
+				t.Errorf("err = %v, want ErrNonceMismatch", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ExchangeCodeForToken: %v", err)
+			}
+		})
+	}
+}
+
+func TestNonceIsUniquePerLogin(t *testing.T) {
+	cachetest.Start(t)
+	idp, cfg := newFakeIdP(t)
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	_, first := idp.authorize(t, as)
+	_, second := idp.authorize(t, as)
+	if first.Get("nonce") == second.Get("nonce") {
+		t.Errorf("two logins share nonce %q", first.Get("nonce"))
+	}
+}
+
+func TestValidateNonce(t *testing.T) {
+	idToken := func(claims jwt.MapClaims) string {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("idp-secret"))
+		return signed
+	}
+
+	tests := []struct {
+		name     string
+		idToken  string
+		expected string
+		wantErr  bool
+	}{
+		{"match", idToken(jwt.MapClaims{"nonce": "n-1"}), "n-1", false},
+		{"mismatch", idToken(jwt.MapClaims{"nonce": "n-2"}), "n-1", true},
+		{"claim missing", idToken(jwt.MapClaims{}), "n-1", true},
+		{"nothing expected", idToken(jwt.MapClaims{"nonce": ""}), "", true},
+		{"no id token", "", "n-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNonce(tt.idToken, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNonceMismatch) {
+				t.Errorf("err = %v, want ErrNonceMismatch", err)
+			}
+		})
+	}
+}
+
+func TestRequireJWTAccessTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessToken string
+		require     bool
+		wantErr     bool
+	}{
+		{"opaque allowed", "", false, false},
+		{"opaque rejected", "", true, true},
+		{"jwt required", "eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJ1c2VyLTEifQ.c2ln", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachetest.Start(t)
+			idp, cfg := newFakeIdP(t)
+			idp.accessToken = tt.accessToken
+			cfg.RequireJWTAccessTokens = tt.require
+			as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+			state, _ := idp.authorize(t, as)
+			_, err := as.ExchangeCodeForToken(context.Background(), state, "auth-code")
+			if tt.wantErr && !errors.Is(err, ErrOpaqueToken) {
+				t.Errorf("err = %v, want ErrOpaqueToken", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ExchangeCodeForToken: %v", err)
+			}
+		})
+	}
+}
+
+func TestRequireJWTAccessTokensConflictsWithIntrospection(t *testing.T) {
+	_, err := NewAuthService(&config.ZitadelConfig{
+		RequireJWTAccessTokens: true,
+		TokenValidationMode:    "introspection",
+	}, &config.JWTConfig{Issuer: "fiber-app-test", TokenTTL: time.Hour}, zap.NewNop())
+	if err == nil {
+		t.Error("RequireJWTAccessTokens accepted together with introspection mode")
+	}
+}
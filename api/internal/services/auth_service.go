@@ -2,24 +2,39 @@ package services
 
 import (
 	"context"
+	"crypto/cipher"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fiber-app/pkg/cache"
 	"fiber-app/pkg/config"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 )
 
 type AuthService struct {
-	config      *config.ZitadelConfig
-	oauthConfig *oauth2.Config
-	logger      *zap.Logger
+	config        *config.ZitadelConfig
+	jwtConfig     *config.JWTConfig
+	oauthConfig   *oauth2.Config
+	logger        *zap.Logger
+	tokenFailures *tokenFailureCounter
+	signingKeys   *signingKeyManager
+	introspection *IntrospectionValidator
+	serviceTokens *serviceTokenCache
+	userInfoCalls singleflight.Group
+	clockSkew     time.Duration
+	sessionCipher cipher.AEAD
 }
 
 type ZitadelUserInfo struct {
@@ -34,14 +49,44 @@ type ZitadelUserInfo struct {
 }
 
 type TokenClaims struct {
-	Sub   string   `json:"sub"`
-	Name  string   `json:"name"`
-	Email string   `json:"email"`
-	Roles []string `json:"urn:zitadel:iam:org:project:roles"`
+	Sub   string        `json:"sub"`
+	Name  string        `json:"name"`
+	Email string        `json:"email"`
+	Roles []string      `json:"urn:zitadel:iam:org:project:roles"`
+	Azp   string        `json:"azp,omitempty"`
+	Acr   string        `json:"acr,omitempty"`
+	Amr   []string      `json:"amr,omitempty"`
+	Cnf   *Confirmation `json:"cnf,omitempty"`
+	Nonce string        `json:"nonce,omitempty"`
+	// Boşlukla ayrılmış OAuth2 scope'ları (RFC 7662 introspection yanıtından)
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewAuthService(cfg *config.ZitadelConfig, logger *zap.Logger) *AuthService {
+// Confirmation - RFC 8705 certificate-bound token confirmation claim
+type Confirmation struct {
+	X5tS256 string `json:"x5t#S256,omitempty"`
+}
+
+// CertThumbprint - Token'ın bağlı olduğu sertifika parmak izi (yoksa boş)
+func (tc *TokenClaims) CertThumbprint() string {
+	if tc.Cnf == nil {
+		return ""
+	}
+	return tc.Cnf.X5tS256
+}
+
+// StepUpRequiredError - Kullanıcının kimlik doğrulama seviyesi yetersiz
+type StepUpRequiredError struct {
+	RequiredACR string
+	RequiredAMR []string
+}
+
+func (e *StepUpRequiredError) Error() string {
+	return fmt.Sprintf("step-up authentication required (acr=%q, amr=%v)", e.RequiredACR, e.RequiredAMR)
+}
+
+func NewAuthService(cfg *config.ZitadelConfig, jwtCfg *config.JWTConfig, logger *zap.Logger) (*AuthService, error) {
 	oauthConfig := &oauth2.Config{
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
@@ -53,33 +98,144 @@ func NewAuthService(cfg *config.ZitadelConfig, logger *zap.Logger) *AuthService
 		},
 	}
 
-	return &AuthService{
-		config:      cfg,
-		oauthConfig: oauthConfig,
-		logger:      logger,
+	if jwtCfg.SigningKeyFile == "" && jwtCfg.RequireSigningKeyFile {
+		return nil, errors.New("JWT_SIGNING_KEY_FILE is required; ephemeral signing keys differ per replica and are lost on restart")
 	}
+
+	signingKeys, err := newSigningKeyManager(jwtCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionCipher, err := newSessionCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwtCfg.SigningKeyFile == "" {
+		logger.Warn("No JWT signing key configured, using an ephemeral key; issued tokens will not survive a restart")
+	}
+
+	logger.Info("JWT signing key loaded",
+		zap.String("kid", signingKeys.current().kid),
+		zap.Int("previous_keys", len(jwtCfg.PreviousKeyFiles)),
+	)
+
+	as := &AuthService{
+		config:        cfg,
+		jwtConfig:     jwtCfg,
+		oauthConfig:   oauthConfig,
+		logger:        logger,
+		tokenFailures: newTokenFailureCounter(),
+		signingKeys:   signingKeys,
+		serviceTokens: newServiceTokenCache(),
+		clockSkew:     effectiveClockSkew(jwtCfg, logger),
+		sessionCipher: sessionCipher,
+	}
+
+	switch cfg.TokenValidationMode {
+	case "", "local":
+	case "introspection":
+		if cfg.RequireJWTAccessTokens {
+			return nil, errors.New("ZITADEL_REQUIRE_JWT_ACCESS_TOKENS cannot be combined with TOKEN_VALIDATION_MODE=introspection")
+		}
+		as.introspection = NewIntrospectionValidator(cfg, logger)
+		logger.Info("Token introspection enabled for non-app tokens")
+	default:
+		return nil, fmt.Errorf("unknown token validation mode: %q", cfg.TokenValidationMode)
+	}
+
+	return as, nil
 }
 
 // GenerateAuthURL - OAuth2 authorization URL oluştur
-func (as *AuthService) GenerateAuthURL() (string, string, error) {
+// redirectURI boşsa yapılandırılmış RedirectURL kullanılır, değilse izin listesine göre doğrulanır
+func (as *AuthService) GenerateAuthURL(redirectURI string) (string, string, error) {
+	if redirectURI != "" {
+		if err := as.ValidateRedirectURI(redirectURI); err != nil {
+			return "", "", err
+		}
+	}
+
 	// State parameter oluştur (CSRF koruması için)
 	state, err := generateRandomString(32)
 	if err != nil {
 		return "", "", err
 	}
 
-	url := as.oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	// ID token replay koruması için nonce
+	nonce, err := generateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	// PKCE verifier ve nonce Redis'te tutulur; callback hangi instance'a düşerse düşsün okunabilir
+	secrets := authFlowSecrets{
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+	}
+	if err := cache.Set(PKCEPrefix+state, secrets, as.config.PKCETTL); err != nil {
+		as.logger.Error("Failed to store PKCE verifier", zap.Error(err))
+		return "", "", err
+	}
+
+	opts := []oauth2.AuthCodeOption{
+		oauth2.AccessTypeOffline,
+		oauth2.S256ChallengeOption(secrets.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", secrets.Nonce),
+	}
+	if redirectURI != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("redirect_uri", redirectURI))
+	}
+	if as.config.RequiredACR != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("acr_values", as.config.RequiredACR))
+	}
+
+	url := as.oauthConfig.AuthCodeURL(state, opts...)
 	return url, state, nil
 }
 
-// ExchangeCodeForToken - Authorization code'u token ile değiştir
-func (as *AuthService) ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := as.oauthConfig.Exchange(ctx, code)
+// authFlowSecrets - Login başlangıcında state ile saklanan, callback'te tek seferlik okunan değerler
+type authFlowSecrets struct {
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	// Login'de varsayılandan farklı redirect_uri seçildiyse token exchange'te de aynısı gönderilmeli
+	RedirectURI string `json:"redirect_uri,omitempty"`
+}
+
+// ExchangeCodeForToken - Authorization code'u, state'e ait PKCE verifier ile token'a değiştir
+// Verifier okunurken silinir, aynı state ile ikinci bir exchange yapılamaz; ID token'daki nonce da doğrulanır
+func (as *AuthService) ExchangeCodeForToken(ctx context.Context, state, code string) (*oauth2.Token, error) {
+	var secrets authFlowSecrets
+	if err := cache.GetDel(PKCEPrefix+state, &secrets); err != nil {
+		as.logger.Warn("PKCE verifier not found for state", zap.Error(err))
+		return nil, fmt.Errorf("pkce verifier not found: %w", err)
+	}
+
+	opts := []oauth2.AuthCodeOption{oauth2.VerifierOption(secrets.CodeVerifier)}
+	if secrets.RedirectURI != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("redirect_uri", secrets.RedirectURI))
+	}
+
+	token, err := as.oauthConfig.Exchange(ctx, code, opts...)
 	if err != nil {
 		as.logger.Error("Token exchange failed", zap.Error(err))
 		return nil, err
 	}
 
+	// Zitadel uygulaması opaque token verecek şekilde yapılandırılmışsa ilk login'de açıkça hata verilir
+	if as.config.RequireJWTAccessTokens && !isJWT(token.AccessToken) {
+		as.logger.Error("Provider issued an opaque access token but JWT access tokens are required; set the Zitadel application's access token type to JWT")
+		return nil, ErrOpaqueToken
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+	if err := ValidateNonce(idToken, secrets.Nonce); err != nil {
+		as.logger.Warn("ID token nonce validation failed", zap.Error(err))
+		return nil, err
+	}
+
 	as.logger.Info("Token exchange successful",
 		zap.String("token_type", token.TokenType),
 		zap.Time("expiry", token.Expiry),
@@ -88,12 +244,176 @@ func (as *AuthService) ExchangeCodeForToken(ctx context.Context, code string) (*
 	return token, nil
 }
 
+// ValidateAuthenticationContext - ID token'daki acr/amr değerlerini gereksinimlerle karşılaştır
+func (as *AuthService) ValidateAuthenticationContext(token *oauth2.Token) error {
+	if as.config.RequiredACR == "" && len(as.config.RequiredAMR) == 0 {
+		return nil
+	}
+
+	stepUp := &StepUpRequiredError{
+		RequiredACR: as.config.RequiredACR,
+		RequiredAMR: as.config.RequiredAMR,
+	}
+
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		as.logger.Warn("ID token missing, authentication context cannot be verified")
+		return stepUp
+	}
+
+	// ID token doğrudan token endpoint'inden (TLS üzerinden) geldiği için imza burada tekrar doğrulanmıyor
+	var claims TokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		as.logger.Error("Failed to parse ID token", zap.Error(err))
+		return err
+	}
+
+	if !claims.satisfies(as.config.RequiredACR, as.config.RequiredAMR) {
+		as.logger.Warn("Authentication context requirements not met",
+			zap.String("sub", claims.Sub),
+			zap.String("acr", claims.Acr),
+			zap.Strings("amr", claims.Amr),
+		)
+		return stepUp
+	}
+
+	return nil
+}
+
+// ErrNonceMismatch - ID token'daki nonce login başlangıcında üretilenle eşleşmiyor (replay)
+var ErrNonceMismatch = errors.New("id token nonce mismatch")
+
+// ValidateNonce - ID token'ın nonce claim'i beklenen değerle eşleşmeli
+func ValidateNonce(idToken, expectedNonce string) error {
+	if idToken == "" {
+		return fmt.Errorf("%w: id token missing", ErrNonceMismatch)
+	}
+
+	// ID token doğrudan token endpoint'inden (TLS üzerinden) geldiği için imza burada tekrar doğrulanmıyor
+	var claims TokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		return err
+	}
+
+	if expectedNonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(expectedNonce)) != 1 {
+		return ErrNonceMismatch
+	}
+
+	return nil
+}
+
+// ErrAzpMismatch - Token başka bir client için verilmiş (azp claim'i client ID ile eşleşmiyor)
+var ErrAzpMismatch = errors.New("authorized party mismatch")
+
+// ValidateAzp - azp claim'i varsa beklenen client ID ile eşleşmeli (expectedClientID boşsa kontrol edilmez)
+func ValidateAzp(claims *TokenClaims, expectedClientID string) error {
+	if expectedClientID == "" || claims.Azp == "" {
+		return nil
+	}
+
+	if claims.Azp != expectedClientID {
+		return fmt.Errorf("%w: got %q", ErrAzpMismatch, claims.Azp)
+	}
+
+	return nil
+}
+
+// ValidateAuthorizedParty - ID token'ın bu client için verildiğini (azp) kontrol et (CheckAzp açıksa)
+func (as *AuthService) ValidateAuthorizedParty(token *oauth2.Token) error {
+	if !as.config.CheckAzp {
+		return nil
+	}
+
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		// azp sadece claim mevcutsa kontrol edilir
+		return nil
+	}
+
+	// ID token doğrudan token endpoint'inden (TLS üzerinden) geldiği için imza burada tekrar doğrulanmıyor
+	var claims TokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		as.logger.Error("Failed to parse ID token", zap.Error(err))
+		return err
+	}
+
+	if err := ValidateAzp(&claims, as.config.ClientID); err != nil {
+		as.logger.Warn("ID token authorized party mismatch",
+			zap.String("sub", claims.Sub),
+			zap.String("azp", claims.Azp),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// satisfies - Claim'ler istenen acr ve tüm amr değerlerini içeriyor mu
+func (tc *TokenClaims) satisfies(requiredACR string, requiredAMR []string) bool {
+	if requiredACR != "" && tc.Acr != requiredACR {
+		return false
+	}
+
+	for _, required := range requiredAMR {
+		found := false
+		for _, method := range tc.Amr {
+			if method == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// userInfoTimeout - Paylaşılan userinfo isteğinin, çağıranların context'inden bağımsız süre sınırı
+const userInfoTimeout = 10 * time.Second
+
 // GetUserInfo - Access token ile kullanıcı bilgilerini al
+// Aynı access token ile eşzamanlı yapılan çağrılar tek bir userinfo isteğini paylaşır
+// Paylaşılan istek ilk çağıranın iptaline bağlı değildir; her çağıran sadece kendi context'i iptal olursa erken döner
 func (as *AuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*ZitadelUserInfo, error) {
+	results := as.userInfoCalls.DoChan(TokenHash(token.AccessToken), func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), userInfoTimeout)
+		defer cancel()
+		return as.fetchUserInfo(fetchCtx, token)
+	})
+
+	var result singleflight.Result
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	if result.Shared {
+		as.logger.Debug("User info request shared with concurrent caller")
+	}
+
+	// Paylaşılan sonuç çağıranlar arasında değiştirilmesin diye kopyalanır
+	userInfo := *result.Val.(*ZitadelUserInfo)
+	userInfo.Roles = append([]string(nil), userInfo.Roles...)
+	return &userInfo, nil
+}
+
+// fetchUserInfo - Userinfo endpoint'inden kullanıcı bilgilerini alır
+func (as *AuthService) fetchUserInfo(ctx context.Context, token *oauth2.Token) (*ZitadelUserInfo, error) {
 	client := as.oauthConfig.Client(ctx, token)
 
 	userInfoURL := fmt.Sprintf("%s/oidc/v1/userinfo", as.config.Domain)
-	resp, err := client.Get(userInfoURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		as.logger.Error("Failed to get user info", zap.Error(err))
 		return nil, err
@@ -122,24 +442,210 @@ func (as *AuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*Z
 	return &userInfo, nil
 }
 
-// ValidateToken - JWT token'ı validate et
-func (as *AuthService) ValidateToken(tokenString string) (*TokenClaims, error) {
-	// Zitadel'den public key alınması gerekir, şimdilik basit validation
+// ValidateToken - Uygulamanın imzaladığı JWT token'ı validate et
+// Introspection modunda uygulamaya ait olmayan (opaque/IdP) token'lar introspection endpoint'i ile doğrulanır;
+// ctx iptal edilirse (istemci bağlantıyı kapattı vb.) introspection isteği de iptal olur
+func (as *AuthService) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	if as.introspection != nil && !as.isAppToken(tokenString) {
+		claims, err := as.introspection.Validate(ctx, tokenString)
+		if err != nil {
+			return nil, as.tokenValidationFailed(err)
+		}
+		return claims, nil
+	}
+
+	// Opaque token'lar JWT parser'ının anlaşılmaz "malformed" hatası yerine açık bir hata ile reddedilir
+	if !isJWT(tokenString) {
+		return nil, as.tokenValidationFailed(ErrOpaqueToken)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(as.jwtConfig.Issuer),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(as.clockSkew),
+	}
+	if as.jwtConfig.Audience != "" {
+		opts = append(opts, jwt.WithAudience(as.jwtConfig.Audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Bu gerçek implementasyonda Zitadel'in public key'i kullanılmalı
-		return []byte("your-secret-key"), nil
-	})
+		// Action token'lar aynı key ile imzalanır ama access token yerine kullanılamaz
+		if typ, _ := token.Header["typ"].(string); typ == actionTokenType {
+			return nil, ErrActionTokenNotAccessToken
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		publicKey, ok := as.signingKeys.publicKey(kid)
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+		return publicKey, nil
+	}, opts...)
 
 	if err != nil {
-		as.logger.Error("Token validation failed", zap.Error(err))
-		return nil, err
+		validationErr := as.tokenValidationFailed(err)
+		if token != nil && onlyExpired(err) {
+			validationErr.ExpiredClaims, _ = token.Claims.(*TokenClaims)
+		}
+		return nil, validationErr
 	}
 
-	if claims, ok := token.Claims.(*TokenClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*TokenClaims)
+	if !ok || !token.Valid {
+		return nil, as.tokenValidationFailed(fmt.Errorf("invalid token"))
+	}
+
+	// Strict modda başka servislere de verilmiş token'lar kabul edilmez (token confusion)
+	if as.jwtConfig.RejectExtraAudiences {
+		if err := validateExactAudience(claims.Audience, as.jwtConfig.Audience); err != nil {
+			return nil, as.tokenValidationFailed(err)
+		}
+	}
+
+	return claims, nil
+}
+
+// validateExactAudience - Token'daki her audience izin verilen audience olmalı
+func validateExactAudience(audiences jwt.ClaimStrings, allowed string) error {
+	for _, aud := range audiences {
+		if aud != allowed {
+			return fmt.Errorf("%w: unexpected audience %q", jwt.ErrTokenInvalidAudience, aud)
+		}
+	}
+	return nil
+}
+
+// effectiveClockSkew - Yapılandırılmış saat farkı toleransını üst sınıra göre kırpar
+// Yanlışlıkla girilen büyük bir değer (örn. 24 saat) süresi dolmuş token'ların kabul edilmesine yol açmasın
+func effectiveClockSkew(jwtCfg *config.JWTConfig, logger *zap.Logger) time.Duration {
+	skew := jwtCfg.ClockSkew
+	if skew < 0 {
+		return 0
+	}
+
+	if skew > jwtCfg.MaxClockSkew {
+		logger.Warn("Configured clock skew exceeds maximum, clamping",
+			zap.Duration("configured", skew),
+			zap.Duration("max", jwtCfg.MaxClockSkew),
+		)
+		return jwtCfg.MaxClockSkew
+	}
+
+	return skew
+}
+
+// isAppToken - Token uygulamanın signing key'lerinden biriyle imzalanmış bir JWT mi (imza burada doğrulanmaz)
+func (as *AuthService) isAppToken(tokenString string) bool {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return false
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	kid, _ := token.Header["kid"].(string)
+	_, ok := as.signingKeys.publicKey(kid)
+	return ok
+}
+
+// tokenValidationFailed - Hatayı kategorize eder, sayacı artırır ve loglar
+func (as *AuthService) tokenValidationFailed(err error) *TokenValidationError {
+	reason := classifyTokenError(err)
+	as.tokenFailures.inc(reason)
+
+	if as.config.LogTokenFailures {
+		fields := []zap.Field{
+			zap.String("reason", string(reason)),
+			zap.Error(err),
+		}
+		if reason.IsClockSkew() {
+			fields = append(fields, zap.Bool("clock_skew_suspected", true))
+		}
+		as.logger.Warn("Token validation failed", fields...)
+	}
+
+	return &TokenValidationError{Reason: reason, Err: err}
+}
+
+// TokenFailureStats - Reason bazında token validation hata sayıları
+func (as *AuthService) TokenFailureStats() map[string]int64 {
+	return as.tokenFailures.snapshot()
+}
+
+// JWKS - Uygulamanın token imzalarını doğrulamak için public key set'i (aktif + önceki key'ler)
+func (as *AuthService) JWKS() JWKSet {
+	return as.signingKeys.jwks()
+}
+
+// ErrNoSigningKeyFile - JWT_SIGNING_KEY_FILE tanımlı değil, key'ler dosyadan yeniden okunamaz
+var ErrNoSigningKeyFile = errors.New("JWT_SIGNING_KEY_FILE is not set")
+
+// ReloadSigningKeys - Aktif ve önceki key'leri JWT_SIGNING_KEY_FILE / JWT_PREVIOUS_KEY_FILES'tan yeniden okur.
+// Tüm replikalar aynı dosyaları okuduğu için rotasyon sonrası hepsi aynı key setini kullanır;
+// okuma başarısız olursa mevcut key'ler değişmeden kalır.
+func (as *AuthService) ReloadSigningKeys() (string, error) {
+	if as.jwtConfig.SigningKeyFile == "" {
+		as.logger.Warn("Signing key reload requested but no key file is configured")
+		return "", ErrNoSigningKeyFile
+	}
+
+	active, previous, err := loadSigningKeys(as.jwtConfig)
+	if err != nil {
+		as.logger.Error("Failed to reload signing keys, keeping current keys", zap.Error(err))
+		return "", err
+	}
+
+	old := as.signingKeys.replace(active, previous)
+	as.logger.Info("JWT signing keys reloaded",
+		zap.String("kid", active.kid),
+		zap.String("previous_kid", old.kid),
+		zap.Int("previous_keys", len(previous)),
+	)
+
+	return active.kid, nil
+}
+
+// Issuer - Uygulamanın imzaladığı token'lardaki issuer
+func (as *AuthService) Issuer() string {
+	return as.jwtConfig.Issuer
+}
+
+// TokenTTL - Uygulamanın imzaladığı token'ların geçerlilik süresi
+func (as *AuthService) TokenTTL() time.Duration {
+	return as.jwtConfig.TokenTTL
+}
+
+// ExpiredTokenSessionFallback - Süresi dolmuş token aktif session ile kabul edilebilir mi
+func (as *AuthService) ExpiredTokenSessionFallback() bool {
+	return as.jwtConfig.ExpiredTokenSessionFallback
+}
+
+// ExpiredTokenGrace - Süresi dolmuş token'ın session ile kabul edilebileceği, exp'ten itibaren azami süre
+func (as *AuthService) ExpiredTokenGrace() time.Duration {
+	return as.jwtConfig.ExpiredTokenGrace
+}
+
+// SessionTTL - Login session'ının saklanma süresi
+// Session fallback açıksa session, token'ın süresi dolduktan sonra grace süresi boyunca da tutulur
+func (as *AuthService) SessionTTL() time.Duration {
+	if as.jwtConfig.ExpiredTokenSessionFallback && as.jwtConfig.ExpiredTokenGrace > 0 {
+		return as.jwtConfig.TokenTTL + as.jwtConfig.ExpiredTokenGrace
+	}
+	return as.jwtConfig.TokenTTL
+}
+
+// AccessTokenCookie - Access token'ın okunabileceği cookie adı (boşsa kullanılmaz)
+func (as *AuthService) AccessTokenCookie() string {
+	return as.jwtConfig.AccessTokenCookie
+}
+
+// MaxPendingStatesPerIP - IP başına bekleyen login state limiti (0 = limitsiz)
+func (as *AuthService) MaxPendingStatesPerIP() int {
+	return as.config.MaxPendingStatesPerIP
+}
+
+// ClientCertHeader - Proxy'nin istemci sertifikasını forward ettiği header (boşsa kullanılmaz)
+func (as *AuthService) ClientCertHeader() string {
+	return as.config.ClientCertHeader
 }
 
 // HasRole - Kullanıcının belirli bir role'ü var mı kontrol et
@@ -165,23 +671,36 @@ func (as *AuthService) HasAnyRole(userInfo *ZitadelUserInfo, requiredRoles []str
 }
 
 // CreateJWTToken - Kullanıcı için JWT token oluştur
-func (as *AuthService) CreateJWTToken(userInfo *ZitadelUserInfo) (string, error) {
+// certThumbprint boş değilse token o istemci sertifikasına bağlanır (cnf claim)
+func (as *AuthService) CreateJWTToken(userInfo *ZitadelUserInfo, certThumbprint string) (string, error) {
+	now := time.Now()
 	claims := TokenClaims{
 		Sub:   userInfo.Sub,
 		Name:  userInfo.Name,
 		Email: userInfo.Email,
 		Roles: userInfo.Roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "fiber-app",
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(as.jwtConfig.TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    as.jwtConfig.Issuer,
 			Subject:   userInfo.Sub,
 		},
 	}
+	if as.jwtConfig.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{as.jwtConfig.Audience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte("your-secret-key"))
+	if certThumbprint != "" {
+		claims.Cnf = &Confirmation{X5tS256: certThumbprint}
+	}
+
+	key := as.signingKeys.current()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.kid
+
+	tokenString, err := token.SignedString(key.privateKey)
 	if err != nil {
 		as.logger.Error("Failed to create JWT token", zap.Error(err))
 		return "", err
@@ -190,12 +709,18 @@ func (as *AuthService) CreateJWTToken(userInfo *ZitadelUserInfo) (string, error)
 	return tokenString, nil
 }
 
-// RevokeToken - Token'ı iptal et
-func (as *AuthService) RevokeToken(ctx context.Context, token *oauth2.Token) error {
+// RevokeToken - Zitadel'in verdiği access veya refresh token'ı iptal et (RFC 7009)
+// tokenTypeHint "access_token" ya da "refresh_token" olabilir
+func (as *AuthService) RevokeToken(ctx context.Context, token, tokenTypeHint string) error {
 	revokeURL := fmt.Sprintf("%s/oauth/v2/revoke", as.config.Domain)
 
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "POST", revokeURL, strings.NewReader(fmt.Sprintf("token=%s", token.AccessToken)))
+	form := url.Values{"token": {token}}
+	if tokenTypeHint != "" {
+		form.Set("token_type_hint", tokenTypeHint)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "POST", revokeURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return err
 	}
@@ -212,15 +737,39 @@ func (as *AuthService) RevokeToken(ctx context.Context, token *oauth2.Token) err
 
 	if resp.StatusCode != http.StatusOK {
 		as.logger.Error("Token revocation request failed",
+			zap.String("token_type_hint", tokenTypeHint),
 			zap.Int("status_code", resp.StatusCode),
 		)
 		return fmt.Errorf("token revocation failed with status: %d", resp.StatusCode)
 	}
 
-	as.logger.Info("Token revoked successfully")
+	as.logger.Info("Token revoked successfully", zap.String("token_type_hint", tokenTypeHint))
 	return nil
 }
 
+// BuildEndSessionURL - Zitadel oturumunu da kapatmak için RP-initiated logout URL'i oluşturur
+// RP logout kapalıysa boş string döner; bu durumda sadece lokal session silinir
+func (as *AuthService) BuildEndSessionURL(idTokenHint, postLogoutRedirectURI string) string {
+	if !as.config.RPLogout {
+		return ""
+	}
+
+	params := url.Values{"client_id": {as.config.ClientID}}
+	if idTokenHint != "" {
+		params.Set("id_token_hint", idTokenHint)
+	}
+	if postLogoutRedirectURI != "" {
+		params.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+	}
+
+	return fmt.Sprintf("%s/oidc/v1/end_session?%s", as.config.Domain, params.Encode())
+}
+
+// PostLogoutRedirectURL - Zitadel logout sonrası yönlendirme adresi
+func (as *AuthService) PostLogoutRedirectURL() string {
+	return as.config.PostLogoutRedirectURL
+}
+
 // generateRandomString - Güvenli random string oluştur
 func generateRandomString(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fiber-app/pkg/config"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// newTestAuthService - Geçici signing key ile, dış servis gerektirmeyen AuthService
+func newTestAuthService(t *testing.T, cfg *config.ZitadelConfig, jwtCfg *config.JWTConfig) *AuthService {
+	t.Helper()
+
+	if jwtCfg.Issuer == "" {
+		jwtCfg.Issuer = "fiber-app-test"
+	}
+	if jwtCfg.TokenTTL == 0 {
+		jwtCfg.TokenTTL = time.Hour
+	}
+
+	as, err := NewAuthService(cfg, jwtCfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	return as
+}
+
+func TestBuildEndSessionURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		rpLogout    bool
+		idTokenHint string
+		redirectURI string
+		want        url.Values
+	}{
+		{"rp logout disabled", false, "id-token", "http://localhost:3000/", nil},
+		{"client id only", true, "", "", url.Values{"client_id": {"test-client"}}},
+		{"with id_token_hint", true, "id-token", "", url.Values{"client_id": {"test-client"}, "id_token_hint": {"id-token"}}},
+		{"with post_logout_redirect_uri", true, "", "http://localhost:3000/?a=b", url.Values{"client_id": {"test-client"}, "post_logout_redirect_uri": {"http://localhost:3000/?a=b"}}},
+		{"with both", true, "id-token", "http://localhost:3000/", url.Values{"client_id": {"test-client"}, "id_token_hint": {"id-token"}, "post_logout_redirect_uri": {"http://localhost:3000/"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := newTestAuthService(t, &config.ZitadelConfig{
+				Domain:   "https://zitadel.example.com",
+				ClientID: "test-client",
+				RPLogout: tt.rpLogout,
+			}, &config.JWTConfig{})
+
+			got := as.BuildEndSessionURL(tt.idTokenHint, tt.redirectURI)
+			if tt.want == nil {
+				if got != "" {
+					t.Errorf("BuildEndSessionURL = %q, want empty", got)
+				}
+				return
+			}
+
+			parsed, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("parse %q: %v", got, err)
+			}
+			if base := parsed.Scheme + "://" + parsed.Host + parsed.Path; base != "https://zitadel.example.com/oidc/v1/end_session" {
+				t.Errorf("endpoint = %q", base)
+			}
+			if parsed.Query().Encode() != tt.want.Encode() {
+				t.Errorf("query = %q, want %q", parsed.Query().Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	var form url.Values
+	var user, pass string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/v2/revoke" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		form = r.PostForm
+		user, pass, _ = r.BasicAuth()
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	as := newTestAuthService(t, &config.ZitadelConfig{
+		Domain:       server.URL,
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+	}, &config.JWTConfig{})
+
+	// Token form-encoded gönderilmeli; özel karakterler parametreyi bozmamalı
+	if err := as.RevokeToken(context.Background(), "a+b&c=d", "refresh_token"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if form.Get("token") != "a+b&c=d" || form.Get("token_type_hint") != "refresh_token" {
+		t.Errorf("form = %v", form)
+	}
+	if user != "test-client" || pass != "test-secret" {
+		t.Errorf("basic auth = %q:%q", user, pass)
+	}
+
+	status = http.StatusBadRequest
+	if err := as.RevokeToken(context.Background(), "token", "access_token"); err == nil {
+		t.Error("RevokeToken should fail on a non-200 response")
+	}
+}
+
+func TestGetUserInfoSharesOneUpstreamCall(t *testing.T) {
+	var calls atomic.Int32
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		entered <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sub":"user-1","email":"test@example.com","urn:zitadel:iam:org:project:roles":["admin"]}`))
+	}))
+	defer server.Close()
+
+	as := newTestAuthService(t, &config.ZitadelConfig{Domain: server.URL}, &config.JWTConfig{})
+	token := &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}
+
+	// İlk çağıranın context'i istek sürerken iptal edilir; diğerleri yine sonucu almalı
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := as.GetUserInfo(firstCtx, token)
+		firstErr <- err
+	}()
+	<-entered
+
+	const callers = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := as.GetUserInfo(context.Background(), token)
+			if err == nil && info.Sub != "user-1" {
+				err = fmt.Errorf("sub = %q", info.Sub)
+			}
+			errs <- err
+		}()
+	}
+
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller error = %v, want context.Canceled", err)
+	}
+
+	// Diğer çağıranların paylaşılan isteğe katılması için kısa süre beklenir
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetUserInfo: %v", err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream calls = %d, want 1", n)
+	}
+}
+
+func TestGetUserInfoReturnsIndependentCopies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sub":"user-1","urn:zitadel:iam:org:project:roles":["admin"]}`))
+	}))
+	defer server.Close()
+
+	as := newTestAuthService(t, &config.ZitadelConfig{Domain: server.URL}, &config.JWTConfig{})
+	token := &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}
+
+	first, err := as.GetUserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	first.Roles[0] = "changed"
+
+	second, err := as.GetUserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if second.Roles[0] != "admin" {
+		t.Errorf("roles = %v, want [admin]", second.Roles)
+	}
+}
+
+func TestValidateAzp(t *testing.T) {
+	tests := []struct {
+		name     string
+		azp      string
+		expected string
+		wantErr  bool
+	}{
+		{"match", "test-client", "test-client", false},
+		{"mismatch", "other-client", "test-client", true},
+		{"missing azp", "", "test-client", false},
+		{"no expected client", "other-client", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAzp(&TokenClaims{Azp: tt.azp}, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrAzpMismatch) {
+				t.Errorf("err = %v, want ErrAzpMismatch", err)
+			}
+		})
+	}
+}
+
+func TestRejectExtraAudiences(t *testing.T) {
+	tests := []struct {
+		name      string
+		audiences jwt.ClaimStrings
+		strict    bool
+		wantErr   bool
+	}{
+		{"allowed only", jwt.ClaimStrings{"fiber-app"}, false, false},
+		{"allowed plus unknown", jwt.ClaimStrings{"fiber-app", "other-service"}, false, false},
+		{"unknown only", jwt.ClaimStrings{"other-service"}, false, true},
+		{"strict allowed only", jwt.ClaimStrings{"fiber-app"}, true, false},
+		{"strict allowed plus unknown", jwt.ClaimStrings{"fiber-app", "other-service"}, true, true},
+		{"strict unknown only", jwt.ClaimStrings{"other-service"}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{
+				Audience:             "fiber-app",
+				RejectExtraAudiences: tt.strict,
+			})
+			key := as.signingKeys.current()
+
+			claims := validClaims(as)
+			claims.Audience = tt.audiences
+			_, err := as.ValidateToken(context.Background(), signClaims(t, claims, key.kid, key.privateKey))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var validationErr *TokenValidationError
+			if err != nil && (!errors.As(err, &validationErr) || validationErr.Reason != TokenFailureWrongAudience) {
+				t.Errorf("err = %v, want reason %q", err, TokenFailureWrongAudience)
+			}
+		})
+	}
+}
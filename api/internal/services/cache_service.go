@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"fiber-app/internal/models"
 	"fiber-app/pkg/cache"
+	"fiber-app/pkg/tracing"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +18,27 @@ const (
 	UserCachePrefix = "user:"
 	RoleCachePrefix = "role:"
 	UserRolePrefix  = "user_role:"
+	AuthStatePrefix = "auth_state:"
+	// Login başlatan isteğin PKCE code verifier'ı (state ile)
+	PKCEPrefix = "pkce:"
+	// Aktif introspection sonuçları (token hash'i ile)
+	IntrospectionPrefix = "introspection:"
+	// IP başına bekleyen login state sayacı
+	AuthStateIPPrefix = "auth_state_ip:"
+	// Aynı state için eşzamanlı callback'leri tekilleştirmek için
+	AuthCallbackLockPrefix = "auth_callback_lock:"
+	// hash(state+code) ile tamamlanmış login işareti (token içermez)
+	AuthResultPrefix = "auth_result:"
+	// Kullanılmış action token jti'leri (tek kullanımlık)
+	ActionTokenUsedPrefix = "action_token_used:"
 
 	// Cache TTL
 	DefaultCacheTTL = 15 * time.Minute
 	RoleCacheTTL    = 30 * time.Minute
+	AuthStateTTL    = 10 * time.Minute
+	// Callback lock'u ve tamamlanan login'in tekrar denemelere 409 döndüğü süre
+	AuthCallbackLockTTL = 30 * time.Second
+	AuthResultTTL       = 30 * time.Second
 )
 
 type CacheService struct {
@@ -31,6 +51,18 @@ func NewCacheService(logger *zap.Logger) *CacheService {
 	}
 }
 
+// WithContext - Log satırlarına context'teki trace_id'yi ekleyen CacheService kopyası döner
+func (cs *CacheService) WithContext(ctx context.Context) *CacheService {
+	traceID := tracing.TraceID(ctx)
+	if traceID == "" {
+		return cs
+	}
+
+	scoped := *cs
+	scoped.logger = cs.logger.With(zap.String("trace_id", traceID))
+	return &scoped
+}
+
 // User Cache Operations
 
 // GetUser - Cache'den user getir
@@ -290,6 +322,11 @@ func (cs *CacheService) InvalidateRoleCaches(roleID uuid.UUID) error {
 		cs.logger.Error("Failed to delete user role caches", zap.Error(err))
 	}
 
+	// User cache'leri Role'ü preload edilmiş haliyle (eski adıyla) tutar
+	if err := cache.DeletePattern(UserCachePrefix + "*"); err != nil {
+		cs.logger.Error("Failed to delete user caches", zap.Error(err))
+	}
+
 	cs.logger.Info("Role caches invalidated",
 		zap.String("role_id", roleID.String()),
 	)
@@ -297,23 +334,132 @@ func (cs *CacheService) InvalidateRoleCaches(roleID uuid.UUID) error {
 	return nil
 }
 
-// GetCacheStats - Cache istatistikleri
-func (cs *CacheService) GetCacheStats() (map[string]interface{}, error) {
+// CacheStats - Prefix bazında key sayıları
+type CacheStats struct {
+	TotalKeys         int64 `json:"total_keys"`
+	UserKeys          int   `json:"user_keys"`
+	RoleKeys          int   `json:"role_keys"`
+	UserRoleKeys      int   `json:"user_role_keys"`
+	PendingAuthStates int   `json:"pending_auth_states"`
+}
+
+// GetCacheStats - Cache istatistikleri, keyspace üzerinden tek SCAN geçişiyle sayılır
+func (cs *CacheService) GetCacheStats() (*CacheStats, error) {
 	dbSize, err := cache.DBSize()
 	if err != nil {
 		return nil, err
 	}
 
-	userKeys, _ := cache.Keys(UserCachePrefix + "*")
-	roleKeys, _ := cache.Keys(RoleCachePrefix + "*")
-	userRoleKeys, _ := cache.Keys(UserRolePrefix + "*")
+	stats := &CacheStats{TotalKeys: dbSize}
+	counters := map[string]*int{
+		UserCachePrefix: &stats.UserKeys,
+		RoleCachePrefix: &stats.RoleKeys,
+		UserRolePrefix:  &stats.UserRoleKeys,
+		AuthStatePrefix: &stats.PendingAuthStates,
+	}
 
-	stats := map[string]interface{}{
-		"total_keys":     dbSize,
-		"user_keys":      len(userKeys),
-		"role_keys":      len(roleKeys),
-		"user_role_keys": len(userRoleKeys),
+	// SCAN aynı key'i birden fazla döndürebilir, sayılan key'ler tekrar sayılmaz
+	counted := make(map[string]struct{})
+	err = cache.ScanBatches("*", cache.DefaultScanCount, func(keys []string) error {
+		for _, key := range keys {
+			if _, ok := counted[key]; ok {
+				continue
+			}
+			for prefix, counter := range counters {
+				if strings.HasPrefix(key, prefix) {
+					counted[key] = struct{}{}
+					*counter++
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return stats, nil
 }
+
+// Auth State Maintenance
+
+// AuthStateCleanup - Auth state temizliğinin sonucu
+type AuthStateCleanup struct {
+	// Temizlik sonrası kalan, TTL'i olan login state'leri ve PKCE verifier'ları
+	PendingStates int
+	PendingPKCE   int
+	// DEL'in gerçekten sildiği key'ler
+	RemovedStates int
+	RemovedPKCE   int
+}
+
+// CleanupAuthStates - Bekleyen login state'lerini ve PKCE verifier'larını raporlar;
+// TTL'i olmayan state'leri ve state'i kalmamış verifier'ları siler
+func (cs *CacheService) CleanupAuthStates() (*AuthStateCleanup, error) {
+	result := &AuthStateCleanup{}
+
+	stateKeys, err := cache.Scan(AuthStatePrefix+"*", cache.DefaultScanCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphanStates []string
+	for _, key := range stateKeys {
+		ttl, err := cache.TTL(key)
+		if err != nil {
+			continue
+		}
+		switch {
+		case ttl == -2:
+			// Scan ile TTL arasında süresi dolmuş
+		case ttl < 0:
+			// TTL -1: expire süresi olmayan key, tamamlanmamış login'den kalmış
+			orphanStates = append(orphanStates, key)
+		default:
+			result.PendingStates++
+		}
+	}
+
+	removed, err := cache.DeleteCount(orphanStates...)
+	if err != nil {
+		cs.logger.Error("Orphaned auth state delete failed", zap.Error(err))
+		return nil, err
+	}
+	result.RemovedStates = int(removed)
+
+	pkceKeys, err := cache.Scan(PKCEPrefix+"*", cache.DefaultScanCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verifier, state'i yoksa callback'te hiç kullanılamaz (reddedilen/süresi dolan ya da yukarıda silinen login).
+	// Login önce verifier'ı sonra state'i yazar; bu aralığa denk gelen tek bir login yeniden denenmek zorunda kalabilir.
+	var orphanPKCE []string
+	for _, key := range pkceKeys {
+		state := strings.TrimPrefix(key, PKCEPrefix)
+		if !cache.Exists(AuthStatePrefix + state) {
+			orphanPKCE = append(orphanPKCE, key)
+			continue
+		}
+		if cache.Exists(key) {
+			result.PendingPKCE++
+		}
+	}
+
+	removed, err = cache.DeleteCount(orphanPKCE...)
+	if err != nil {
+		cs.logger.Error("Orphaned PKCE verifier delete failed", zap.Error(err))
+		return nil, err
+	}
+	result.RemovedPKCE = int(removed)
+
+	cs.logger.Info("Auth states cleaned up",
+		zap.Int("pending_states", result.PendingStates),
+		zap.Int("pending_pkce", result.PendingPKCE),
+		zap.Int("removed_states", result.RemovedStates),
+		zap.Int("removed_pkce", result.RemovedPKCE),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/tracing"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCleanupAuthStates(t *testing.T) {
+	redis := cachetest.Start(t)
+	cs := NewCacheService(zap.NewNop())
+
+	for _, state := range []string{"pending-1", "pending-2"} {
+		if err := cache.Set(AuthStatePrefix+state, map[string]string{"ip": "10.0.0.1"}, time.Minute); err != nil {
+			t.Fatalf("cache.Set: %v", err)
+		}
+		if err := cache.Set(PKCEPrefix+state, map[string]string{"verifier": "v"}, time.Minute); err != nil {
+			t.Fatalf("cache.Set: %v", err)
+		}
+	}
+	// TTL'siz key'ler tamamlanmamış login'lerden kalan orphan'lar; verifier'ları da state'le birlikte gider
+	redis.Set(AuthStatePrefix+"orphan-1", `{}`)
+	redis.Set(AuthStatePrefix+"orphan-2", `{}`)
+	redis.Set(PKCEPrefix+"orphan-1", `{}`)
+	// Limit/store hatasıyla reddedilmiş login'in state'siz verifier'ı
+	if err := cache.Set(PKCEPrefix+"rejected", map[string]string{"verifier": "v"}, time.Minute); err != nil {
+		t.Fatalf("cache.Set: %v", err)
+	}
+	redis.Set(UserCachePrefix+"1", `{}`)
+
+	result, err := cs.CleanupAuthStates()
+	if err != nil {
+		t.Fatalf("CleanupAuthStates: %v", err)
+	}
+	want := AuthStateCleanup{PendingStates: 2, PendingPKCE: 2, RemovedStates: 2, RemovedPKCE: 2}
+	if *result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+
+	for _, key := range []string{
+		AuthStatePrefix + "orphan-1", AuthStatePrefix + "orphan-2",
+		PKCEPrefix + "orphan-1", PKCEPrefix + "rejected",
+	} {
+		if redis.Exists(key) {
+			t.Errorf("%s was not removed", key)
+		}
+	}
+	for _, key := range []string{
+		AuthStatePrefix + "pending-1", AuthStatePrefix + "pending-2",
+		PKCEPrefix + "pending-1", PKCEPrefix + "pending-2",
+		UserCachePrefix + "1",
+	} {
+		if !redis.Exists(key) {
+			t.Errorf("%s should be kept", key)
+		}
+	}
+}
+
+func TestGetCacheStats(t *testing.T) {
+	redis := cachetest.Start(t)
+	cs := NewCacheService(zap.NewNop())
+
+	seed := map[string]int{
+		UserCachePrefix: 1200,
+		RoleCachePrefix: 3,
+		UserRolePrefix:  40,
+		AuthStatePrefix: 7,
+		"session:":      25,
+	}
+	for prefix, n := range seed {
+		for i := 0; i < n; i++ {
+			redis.Set(fmt.Sprintf("%s%d", prefix, i), "{}")
+		}
+	}
+
+	stats, err := cs.GetCacheStats()
+	if err != nil {
+		t.Fatalf("GetCacheStats: %v", err)
+	}
+
+	want := CacheStats{TotalKeys: 1275, UserKeys: 1200, RoleKeys: 3, UserRoleKeys: 40, PendingAuthStates: 7}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	// Keyspace tek SCAN geçişiyle sayılır, prefix başına ayrı tarama ya da KEYS yapılmaz
+	if got := redis.CommandCount("KEYS"); got != 0 {
+		t.Errorf("KEYS calls = %d, want 0", got)
+	}
+	if got, want := redis.CommandCount("SCAN"), (1275+cache.DefaultScanCount-1)/cache.DefaultScanCount; got != want {
+		t.Errorf("SCAN calls = %d, want %d for a single pass", got, want)
+	}
+}
+
+func TestCacheServiceLogsCarryTraceID(t *testing.T) {
+	cachetest.Start(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	cs := NewCacheService(zap.New(core))
+
+	user := &models.User{ID: uuid.New()}
+	ctx := tracing.WithTraceID(context.Background(), "trace-abc")
+	if err := cs.WithContext(ctx).SetUser(user); err != nil {
+		t.Fatalf("SetUser: %v", err)
+	}
+
+	entries := logs.FilterMessage("User cached").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["trace_id"]; got != "trace-abc" {
+		t.Errorf("trace_id = %v, want trace-abc", got)
+	}
+
+	// Trace'siz context aynı servisi döner, loglara trace_id eklenmez
+	if scoped := cs.WithContext(context.Background()); scoped != cs {
+		t.Error("WithContext without a trace returned a new service")
+	}
+	if err := cs.SetUser(user); err != nil {
+		t.Fatalf("SetUser: %v", err)
+	}
+	entries = logs.FilterMessage("User cached").All()
+	if _, ok := entries[len(entries)-1].ContextMap()["trace_id"]; ok {
+		t.Error("trace_id logged without a trace-carrying context")
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/config"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// ErrTokenInactive - Introspection endpoint'i token'ı aktif değil olarak döndü
+var ErrTokenInactive = errors.New("token is not active")
+
+// IntrospectionValidator - Opaque access token'ları RFC 7662 introspection ile doğrular
+type IntrospectionValidator struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	cacheTTL     time.Duration
+	httpClient   *http.Client
+	logger       *zap.Logger
+}
+
+// introspectionResponse - RFC 7662 yanıtı (+ Zitadel kullanıcı/rol claim'leri)
+type introspectionResponse struct {
+	Active   bool             `json:"active"`
+	Sub      string           `json:"sub"`
+	Scope    string           `json:"scope"`
+	ClientID string           `json:"client_id"`
+	Iss      string           `json:"iss"`
+	Aud      jwt.ClaimStrings `json:"aud"`
+	Exp      int64            `json:"exp"`
+	Iat      int64            `json:"iat"`
+	Nbf      int64            `json:"nbf"`
+	Jti      string           `json:"jti"`
+	Name     string           `json:"name"`
+	Email    string           `json:"email"`
+	Roles    json.RawMessage  `json:"urn:zitadel:iam:org:project:roles"`
+}
+
+// NewIntrospectionValidator - Zitadel introspection endpoint'i için validator
+func NewIntrospectionValidator(cfg *config.ZitadelConfig, logger *zap.Logger) *IntrospectionValidator {
+	return &IntrospectionValidator{
+		endpoint:     fmt.Sprintf("%s/oauth/v2/introspect", cfg.Domain),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		cacheTTL:     cfg.IntrospectionCacheTTL,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		logger:       logger,
+	}
+}
+
+// Validate - Token'ı introspect eder, aktifse claim'lerini döner
+// Aktif token sonuçları kısa süre cache'lenir (token'ın kendisi değil hash'i key olarak kullanılır)
+func (iv *IntrospectionValidator) Validate(ctx context.Context, token string) (*TokenClaims, error) {
+	cacheKey := IntrospectionPrefix + TokenHash(token)
+
+	var cached TokenClaims
+	if iv.cacheTTL > 0 && cache.Get(cacheKey, &cached) == nil {
+		return &cached, nil
+	}
+
+	resp, err := iv.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Active {
+		return nil, ErrTokenInactive
+	}
+
+	claims := resp.claims()
+
+	// Cache süresi token'ın kalan ömrünü aşmamalı
+	ttl := iv.cacheTTL
+	if resp.Exp > 0 {
+		if remaining := time.Until(time.Unix(resp.Exp, 0)); remaining < ttl {
+			ttl = remaining
+		}
+	}
+	if ttl > 0 {
+		if err := cache.Set(cacheKey, claims, ttl); err != nil {
+			iv.logger.Warn("Failed to cache introspection result", zap.Error(err))
+		}
+	}
+
+	return claims, nil
+}
+
+// introspect - Introspection endpoint'ine client authentication ile istek atar
+func (iv *IntrospectionValidator) introspect(ctx context.Context, token string) (*introspectionResponse, error) {
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, iv.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(iv.clientID), url.QueryEscape(iv.clientSecret))
+
+	resp, err := iv.httpClient.Do(req)
+	if err != nil {
+		iv.logger.Error("Token introspection request failed", zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		iv.logger.Error("Token introspection failed",
+			zap.Int("status_code", resp.StatusCode),
+		)
+		return nil, fmt.Errorf("token introspection failed with status: %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		iv.logger.Error("Failed to decode introspection response", zap.Error(err))
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// claims - Introspection yanıtını TokenClaims'e çevirir
+func (r *introspectionResponse) claims() *TokenClaims {
+	claims := &TokenClaims{
+		Sub:   r.Sub,
+		Name:  r.Name,
+		Email: r.Email,
+		Roles: parseRoleClaim(r.Roles),
+		Scope: r.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  r.Sub,
+			Issuer:   r.Iss,
+			Audience: r.Aud,
+			ID:       r.Jti,
+		},
+	}
+
+	if r.Exp > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(r.Exp, 0))
+	}
+	if r.Iat > 0 {
+		claims.IssuedAt = jwt.NewNumericDate(time.Unix(r.Iat, 0))
+	}
+	if r.Nbf > 0 {
+		claims.NotBefore = jwt.NewNumericDate(time.Unix(r.Nbf, 0))
+	}
+
+	return claims
+}
+
+// parseRoleClaim - Rol claim'i string dizisi veya Zitadel formatında (rol -> org) object olabilir
+func parseRoleClaim(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var roles []string
+	if err := json.Unmarshal(raw, &roles); err == nil {
+		return roles
+	}
+
+	var roleMap map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &roleMap); err == nil {
+		for role := range roleMap {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// TokenHash - Token'ın cache key'lerinde ve session'da saklanan SHA-256 hash'i
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
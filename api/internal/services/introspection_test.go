@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// introspectionServer - Verilen yanıtı dönen, istekleri sayan sahte introspection endpoint'i
+func introspectionServer(t *testing.T, status int, response map[string]interface{}) (*config.ZitadelConfig, *int32) {
+	t.Helper()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		if r.URL.Path != "/oauth/v2/introspect" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "test-client" || secret != "test-secret" {
+			t.Errorf("basic auth = %q/%q (%v), want client credentials", id, secret, ok)
+		}
+		if r.FormValue("token") == "" || r.FormValue("token_type_hint") != "access_token" {
+			t.Errorf("form = %v, want token and token_type_hint", r.Form)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &config.ZitadelConfig{
+		Domain:                srv.URL,
+		ClientID:              "test-client",
+		ClientSecret:          "test-secret",
+		TokenValidationMode:   "introspection",
+		IntrospectionCacheTTL: time.Minute,
+	}, &calls
+}
+
+func TestIntrospectionMapsClaims(t *testing.T) {
+	cachetest.Start(t)
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	cfg, _ := introspectionServer(t, http.StatusOK, map[string]interface{}{
+		"active": true,
+		"sub":    "user-1",
+		"scope":  "openid profile",
+		"iss":    "https://idp.example.com",
+		"exp":    exp.Unix(),
+		"email":  "user@example.com",
+		"urn:zitadel:iam:org:project:roles": map[string]interface{}{
+			"admin":  map[string]string{"org-1": "example.com"},
+			"editor": map[string]string{"org-1": "example.com"},
+		},
+	})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	claims, err := as.ValidateToken(context.Background(), "opaque-access-token")
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+
+	sort.Strings(claims.Roles)
+	if claims.Sub != "user-1" || claims.Subject != "user-1" || claims.Email != "user@example.com" {
+		t.Errorf("claims = %+v, want sub user-1 and email", claims)
+	}
+	if claims.Scope != "openid profile" {
+		t.Errorf("scope = %q, want %q", claims.Scope, "openid profile")
+	}
+	if len(claims.Roles) != 2 || claims.Roles[0] != "admin" || claims.Roles[1] != "editor" {
+		t.Errorf("roles = %v, want [admin editor]", claims.Roles)
+	}
+	if claims.ExpiresAt == nil || !claims.ExpiresAt.Time.Equal(exp) {
+		t.Errorf("exp = %v, want %v", claims.ExpiresAt, exp)
+	}
+}
+
+func TestParseRoleClaim(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{`["admin","editor"]`, []string{"admin", "editor"}},
+		{`{"admin":{"org-1":"example.com"}}`, []string{"admin"}},
+		{``, nil},
+		{`"admin"`, nil},
+	}
+
+	for _, tt := range tests {
+		got := parseRoleClaim(json.RawMessage(tt.raw))
+		sort.Strings(got)
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("parseRoleClaim(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestIntrospectionCachesActiveResults(t *testing.T) {
+	redis := cachetest.Start(t)
+	cfg, calls := introspectionServer(t, http.StatusOK, map[string]interface{}{
+		"active": true,
+		"sub":    "user-1",
+		"scope":  "openid email",
+		// Token'ın kalan ömrü cache süresinden kısa
+		"exp": time.Now().Add(20 * time.Second).Unix(),
+	})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	for i := 0; i < 3; i++ {
+		claims, err := as.ValidateToken(context.Background(), "opaque-access-token")
+		if err != nil {
+			t.Fatalf("ValidateToken: %v", err)
+		}
+		// Cache'ten dönen claim'lerde de scope korunur
+		if claims.Scope != "openid email" {
+			t.Errorf("call %d: scope = %q, want %q", i+1, claims.Scope, "openid email")
+		}
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("introspection endpoint called %d times, want 1", got)
+	}
+
+	key := IntrospectionPrefix + TokenHash("opaque-access-token")
+	if !redis.Exists(key) {
+		t.Fatalf("%s was not cached", key)
+	}
+	if ttl := redis.TTL(key); ttl <= 0 || ttl > 20*time.Second {
+		t.Errorf("cache ttl = %v, want capped at the token's remaining 20s", ttl)
+	}
+	if len(redis.Keys("*opaque-access-token*")) != 0 {
+		t.Error("raw token used in a cache key")
+	}
+}
+
+func TestIntrospectionWithoutCache(t *testing.T) {
+	cachetest.Start(t)
+	cfg, calls := introspectionServer(t, http.StatusOK, map[string]interface{}{"active": true, "sub": "user-1"})
+	cfg.IntrospectionCacheTTL = 0
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	as.ValidateToken(context.Background(), "opaque-access-token")
+	as.ValidateToken(context.Background(), "opaque-access-token")
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("introspection endpoint called %d times, want 2", got)
+	}
+}
+
+func TestIntrospectionRejectsInactiveToken(t *testing.T) {
+	redis := cachetest.Start(t)
+	cfg, calls := introspectionServer(t, http.StatusOK, map[string]interface{}{"active": false})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	for i := 0; i < 2; i++ {
+		_, err := as.ValidateToken(context.Background(), "revoked-token")
+
+		var validationErr *TokenValidationError
+		if !errors.As(err, &validationErr) || validationErr.Reason != TokenFailureInactive {
+			t.Fatalf("err = %v, want reason %q", err, TokenFailureInactive)
+		}
+	}
+	// Negatif sonuçlar cache'lenmez
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("introspection endpoint called %d times, want 2", got)
+	}
+	if keys := redis.Keys(IntrospectionPrefix + "*"); len(keys) != 0 {
+		t.Errorf("inactive result cached: %v", keys)
+	}
+}
+
+func TestIntrospectionEndpointError(t *testing.T) {
+	cachetest.Start(t)
+	cfg, _ := introspectionServer(t, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	if _, err := as.introspection.Validate(context.Background(), "opaque-access-token"); err == nil {
+		t.Error("Validate succeeded on a 401 introspection response")
+	}
+}
+
+func TestIntrospectionUsesCallerContext(t *testing.T) {
+	cachetest.Start(t)
+	cfg, calls := introspectionServer(t, http.StatusOK, map[string]interface{}{"active": true, "sub": "user-1"})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	// İstek iptal edildiyse (istemci gitti) introspection endpoint'ine gidilmez
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := as.ValidateToken(ctx, "opaque-access-token"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("introspection endpoint called %d times with a cancelled context", got)
+	}
+}
+
+func TestIntrospectionModeValidatesAppTokensLocally(t *testing.T) {
+	cachetest.Start(t)
+	cfg, calls := introspectionServer(t, http.StatusOK, map[string]interface{}{"active": true})
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	token, err := as.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+	if _, err := as.ValidateToken(context.Background(), token); err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("app token sent to introspection %d times", got)
+	}
+}
+
+func TestTokenValidationMode(t *testing.T) {
+	for _, mode := range []string{"", "local"} {
+		as := newTestAuthService(t, &config.ZitadelConfig{TokenValidationMode: mode}, &config.JWTConfig{})
+		if as.introspection != nil {
+			t.Errorf("mode %q enabled introspection", mode)
+		}
+	}
+
+	_, err := NewAuthService(&config.ZitadelConfig{TokenValidationMode: "remote"}, &config.JWTConfig{
+		Issuer:   "fiber-app-test",
+		TokenTTL: time.Hour,
+	}, zap.NewNop())
+	if err == nil {
+		t.Error("unknown validation mode accepted")
+	}
+}
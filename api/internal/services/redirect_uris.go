@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrRedirectURINotAllowed - İstenen redirect_uri izin listesinde değil
+var ErrRedirectURINotAllowed = errors.New("redirect uri not allowed")
+
+// ValidateRedirectURI - Login için istenen redirect_uri'nin izinli olup olmadığını kontrol eder
+// İzinli olanlar (RFC 8252):
+//   - RedirectURL veya AllowedRedirectURLs ile birebir aynı URL'ler
+//   - İzinli bir loopback URL'i ile sadece portu farklı olan http://127.0.0.1 / http://[::1] URL'leri
+//   - NativeRedirectSchemes içindeki custom scheme'ler (örn. myapp://callback)
+func (as *AuthService) ValidateRedirectURI(rawURI string) error {
+	uri, err := url.Parse(rawURI)
+	if err != nil || uri.Scheme == "" || uri.Fragment != "" {
+		return ErrRedirectURINotAllowed
+	}
+
+	allowed := append([]string{as.config.RedirectURL}, as.config.AllowedRedirectURLs...)
+	for _, candidate := range allowed {
+		if rawURI == candidate {
+			return nil
+		}
+	}
+
+	// Native uygulamalar loopback'te rastgele port açar; port hariç eşleşme yeterli
+	if uri.Scheme == "http" && isLoopbackIP(uri.Hostname()) {
+		for _, candidate := range allowed {
+			allowedURI, err := url.Parse(candidate)
+			if err != nil || allowedURI.Scheme != "http" || !isLoopbackIP(allowedURI.Hostname()) {
+				continue
+			}
+			if allowedURI.Hostname() == uri.Hostname() && allowedURI.Path == uri.Path && allowedURI.RawQuery == uri.RawQuery {
+				return nil
+			}
+		}
+		return ErrRedirectURINotAllowed
+	}
+
+	// http/https sadece birebir eşleşmeyle kabul edilir; scheme listesi bunları açamaz
+	if uri.Scheme == "http" || uri.Scheme == "https" {
+		return ErrRedirectURINotAllowed
+	}
+
+	for _, scheme := range as.config.NativeRedirectSchemes {
+		if strings.EqualFold(uri.Scheme, scheme) {
+			return nil
+		}
+	}
+
+	return ErrRedirectURINotAllowed
+}
+
+// isLoopbackIP - Host bir loopback IP literal'i mi ("localhost" kabul edilmez, RFC 8252 8.3)
+func isLoopbackIP(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fiber-app/pkg/cache/cachetest"
+	"fiber-app/pkg/config"
+	"net/url"
+	"testing"
+)
+
+func TestValidateRedirectURI(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{
+		ClientID:              "test-client",
+		RedirectURL:           "https://app.example.com/api/v1/auth/callback",
+		AllowedRedirectURLs:   []string{"http://127.0.0.1/callback", "http://[::1]/callback"},
+		NativeRedirectSchemes: []string{"com.example.app"},
+	}, &config.JWTConfig{})
+
+	tests := []struct {
+		uri     string
+		allowed bool
+	}{
+		{"https://app.example.com/api/v1/auth/callback", true},
+		{"com.example.app:/oauth2redirect", true},
+		{"COM.EXAMPLE.APP://callback", true},
+		{"http://127.0.0.1:51234/callback", true},
+		{"http://[::1]:8000/callback", true},
+
+		{"myapp://callback", false},
+		{"javascript:alert(1)", false},
+		{"http://127.0.0.1:51234/other", false},
+		{"http://localhost:51234/callback", false},
+		{"http://127.0.0.1:51234/callback?next=/admin", false},
+		{"https://app.example.com/api/v1/auth/callback/", false},
+		{"https://evil.example.com/api/v1/auth/callback", false},
+		{"com.example.app:/oauth2redirect#token", false},
+		{"/relative/callback", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			err := as.ValidateRedirectURI(tt.uri)
+			if tt.allowed && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrRedirectURINotAllowed) {
+				t.Errorf("err = %v, want ErrRedirectURINotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestSchemeListDoesNotOpenHTTP(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{
+		RedirectURL:           "https://app.example.com/callback",
+		NativeRedirectSchemes: []string{"http", "https"},
+	}, &config.JWTConfig{})
+
+	for _, uri := range []string{"https://evil.example.com/callback", "http://evil.example.com/callback"} {
+		if err := as.ValidateRedirectURI(uri); err == nil {
+			t.Errorf("%s accepted through the scheme list", uri)
+		}
+	}
+}
+
+func TestNativeRedirectThroughAuthFlow(t *testing.T) {
+	redis := cachetest.Start(t)
+	idp, cfg := newFakeIdP(t)
+	cfg.NativeRedirectSchemes = []string{"com.example.app"}
+	as := newTestAuthService(t, cfg, &config.JWTConfig{})
+
+	const native = "com.example.app:/oauth2redirect"
+	authURL, state, err := as.GenerateAuthURL(native)
+	if err != nil {
+		t.Fatalf("GenerateAuthURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	if got := u.Query().Get("redirect_uri"); got != native {
+		t.Errorf("auth url redirect_uri = %q, want %q", got, native)
+	}
+
+	// Token exchange'te login'de seçilen redirect_uri gönderilmeli
+	idp.nonce = u.Query().Get("nonce")
+	if _, err := as.ExchangeCodeForToken(context.Background(), state, "auth-code"); err != nil {
+		t.Fatalf("ExchangeCodeForToken: %v", err)
+	}
+	if idp.redirectURI != native {
+		t.Errorf("exchange redirect_uri = %q, want %q", idp.redirectURI, native)
+	}
+
+	if _, _, err := as.GenerateAuthURL("otherapp://callback"); !errors.Is(err, ErrRedirectURINotAllowed) {
+		t.Errorf("err = %v, want ErrRedirectURINotAllowed", err)
+	}
+	if keys := redis.Keys(PKCEPrefix + "*"); len(keys) != 0 {
+		t.Errorf("rejected login left state in Redis: %v", keys)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// serviceTokenRefreshMargin - Cache'teki service token'ı süresi dolmadan bu kadar önce yenilenir
+const serviceTokenRefreshMargin = 30 * time.Second
+
+// serviceTokenCache - Scope setine göre client credentials token'ları (process içi)
+type serviceTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]*oauth2.Token
+}
+
+func newServiceTokenCache() *serviceTokenCache {
+	return &serviceTokenCache{tokens: make(map[string]*oauth2.Token)}
+}
+
+// GetServiceToken - Kullanıcı olmadan (background job'lar vb.) korumalı endpoint'leri çağırmak için
+// client_credentials grant ile token alır; token süresi dolmaya yaklaşana kadar cache'ten döner
+func (as *AuthService) GetServiceToken(ctx context.Context, scopes []string) (*oauth2.Token, error) {
+	key := serviceTokenKey(scopes)
+
+	as.serviceTokens.mu.Lock()
+	defer as.serviceTokens.mu.Unlock()
+
+	if token, ok := as.serviceTokens.tokens[key]; ok && serviceTokenFresh(token) {
+		return token, nil
+	}
+
+	ccConfig := &clientcredentials.Config{
+		ClientID:     as.config.ClientID,
+		ClientSecret: as.config.ClientSecret,
+		TokenURL:     as.oauthConfig.Endpoint.TokenURL,
+		Scopes:       scopes,
+	}
+
+	token, err := ccConfig.Token(ctx)
+	if err != nil {
+		as.logger.Error("Client credentials token request failed",
+			zap.Strings("scopes", scopes),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	as.serviceTokens.tokens[key] = token
+
+	as.logger.Debug("Service token obtained",
+		zap.Strings("scopes", scopes),
+		zap.Time("expiry", token.Expiry),
+	)
+
+	return token, nil
+}
+
+// serviceTokenFresh - Token geçerli ve yenileme payından daha uzun süre geçerli kalacak mı
+func serviceTokenFresh(token *oauth2.Token) bool {
+	if token.AccessToken == "" {
+		return false
+	}
+	if token.Expiry.IsZero() {
+		return true
+	}
+	return time.Until(token.Expiry) > serviceTokenRefreshMargin
+}
+
+// serviceTokenKey - Scope sırasından bağımsız cache key'i
+func serviceTokenKey(scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-app/pkg/config"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// clientCredentialsServer - Her istekte yeni token veren client_credentials endpoint'i
+func clientCredentialsServer(t *testing.T, expiresIn int) (*AuthService, *int32, *string) {
+	t.Helper()
+
+	var calls int32
+	var scope string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "client_credentials" {
+			t.Errorf("grant_type = %q, want client_credentials", r.FormValue("grant_type"))
+		}
+		if id, secret, _ := r.BasicAuth(); id != "test-client" || secret != "test-secret" {
+			t.Errorf("client auth = %q:%q", id, secret)
+		}
+		scope = r.FormValue("scope")
+
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("service-token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	as := newTestAuthService(t, &config.ZitadelConfig{
+		Domain:       srv.URL,
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+	}, &config.JWTConfig{})
+	return as, &calls, &scope
+}
+
+func TestServiceTokenIsCached(t *testing.T) {
+	as, calls, scope := clientCredentialsServer(t, 3600)
+	ctx := context.Background()
+
+	first, err := as.GetServiceToken(ctx, []string{"openid", "urn:zitadel:iam:org:project:id:zitadel:aud"})
+	if err != nil {
+		t.Fatalf("GetServiceToken: %v", err)
+	}
+	if *scope != "openid urn:zitadel:iam:org:project:id:zitadel:aud" {
+		t.Errorf("scope = %q", *scope)
+	}
+
+	// Scope sırası cache key'ini değiştirmez
+	second, err := as.GetServiceToken(ctx, []string{"urn:zitadel:iam:org:project:id:zitadel:aud", "openid"})
+	if err != nil {
+		t.Fatalf("GetServiceToken: %v", err)
+	}
+	if second.AccessToken != first.AccessToken || atomic.LoadInt32(calls) != 1 {
+		t.Errorf("second call hit the endpoint (%d calls, token %q)", atomic.LoadInt32(calls), second.AccessToken)
+	}
+
+	// Farklı scope seti ayrı token alır
+	if other, _ := as.GetServiceToken(ctx, []string{"openid"}); other.AccessToken == first.AccessToken {
+		t.Error("different scopes share a token")
+	}
+}
+
+func TestServiceTokenRefreshesBeforeExpiry(t *testing.T) {
+	// Yenileme payından (30s) kısa ömürlü token her çağrıda yenilenir
+	as, calls, _ := clientCredentialsServer(t, 10)
+	ctx := context.Background()
+
+	first, err := as.GetServiceToken(ctx, []string{"openid"})
+	if err != nil {
+		t.Fatalf("GetServiceToken: %v", err)
+	}
+	second, err := as.GetServiceToken(ctx, []string{"openid"})
+	if err != nil {
+		t.Fatalf("GetServiceToken: %v", err)
+	}
+	if second.AccessToken == first.AccessToken || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("expiring token was not refreshed (%d calls)", atomic.LoadInt32(calls))
+	}
+}
+
+func TestServiceTokenEndpointError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer srv.Close()
+
+	as := newTestAuthService(t, &config.ZitadelConfig{Domain: srv.URL, ClientID: "test-client"}, &config.JWTConfig{})
+	if _, err := as.GetServiceToken(context.Background(), nil); err == nil {
+		t.Error("GetServiceToken succeeded on invalid_client")
+	}
+}
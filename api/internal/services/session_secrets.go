@@ -0,0 +1,67 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fiber-app/pkg/config"
+	"fmt"
+)
+
+// sessionKeyContext - Client secret'tan session anahtarı türetilirken kullanılan ayırıcı
+const sessionKeyContext = "fiber-app session secrets v1\x00"
+
+// ErrSessionSecretInvalid - Şifreli session değeri çözülemedi (bozuk, başka key ya da başka kullanıcı)
+var ErrSessionSecretInvalid = errors.New("session secret cannot be decrypted")
+
+// newSessionCipher - Session'da saklanan IdP token'ları için AES-256-GCM.
+// SESSION_ENCRYPTION_KEY (base64, 32 byte) yoksa key client secret'tan türetilir; tüm replikalar aynı key'i kullanır
+func newSessionCipher(cfg *config.ZitadelConfig) (cipher.AEAD, error) {
+	var key []byte
+	if cfg.SessionEncryptionKey != "" {
+		decoded, err := base64.StdEncoding.DecodeString(cfg.SessionEncryptionKey)
+		if err != nil || len(decoded) != 32 {
+			return nil, errors.New("SESSION_ENCRYPTION_KEY must be 32 bytes, base64 encoded")
+		}
+		key = decoded
+	} else {
+		sum := sha256.Sum256([]byte(sessionKeyContext + cfg.ClientSecret))
+		key = sum[:]
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealSessionSecret - IdP token'ını session'a yazılmak üzere şifreler.
+// Şifreli değer subject'e bağlıdır; başka kullanıcının session'ına taşınırsa çözülemez
+func (as *AuthService) SealSessionSecret(subject, secret string) (string, error) {
+	nonce := make([]byte, as.sessionCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := as.sessionCipher.Seal(nonce, nonce, []byte(secret), []byte(subject))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenSessionSecret - SealSessionSecret ile şifrelenmiş değeri çözer
+func (as *AuthService) OpenSessionSecret(subject, sealed string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < as.sessionCipher.NonceSize() {
+		return "", ErrSessionSecretInvalid
+	}
+
+	nonce, ciphertext := data[:as.sessionCipher.NonceSize()], data[as.sessionCipher.NonceSize():]
+	plaintext, err := as.sessionCipher.Open(nil, nonce, ciphertext, []byte(subject))
+	if err != nil {
+		return "", ErrSessionSecretInvalid
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fiber-app/pkg/config"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSessionSecretRoundTrip(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{ClientSecret: "secret"}, &config.JWTConfig{})
+
+	sealed, err := as.SealSessionSecret("user-1", "idp-refresh-token")
+	if err != nil {
+		t.Fatalf("SealSessionSecret: %v", err)
+	}
+	if strings.Contains(sealed, "idp-refresh-token") {
+		t.Fatal("sealed value contains the plaintext token")
+	}
+
+	opened, err := as.OpenSessionSecret("user-1", sealed)
+	if err != nil || opened != "idp-refresh-token" {
+		t.Fatalf("OpenSessionSecret = %q, %v", opened, err)
+	}
+
+	// Her şifrelemede yeni nonce kullanılır
+	again, _ := as.SealSessionSecret("user-1", "idp-refresh-token")
+	if again == sealed {
+		t.Error("sealing the same value twice produced identical output")
+	}
+}
+
+func TestSessionSecretRejections(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{ClientSecret: "secret"}, &config.JWTConfig{})
+	sealed, _ := as.SealSessionSecret("user-1", "idp-access-token")
+
+	// Farklı key'le çalışan (yanlış yapılandırılmış) replika
+	other := newTestAuthService(t, &config.ZitadelConfig{ClientSecret: "other-secret"}, &config.JWTConfig{})
+
+	tampered := []byte(sealed)
+	tampered[len(tampered)-1] ^= 'A' ^ 'B'
+
+	for name, open := range map[string]func() (string, error){
+		"other subject": func() (string, error) { return as.OpenSessionSecret("user-2", sealed) },
+		"other key":     func() (string, error) { return other.OpenSessionSecret("user-1", sealed) },
+		"tampered":      func() (string, error) { return as.OpenSessionSecret("user-1", string(tampered)) },
+		"plaintext":     func() (string, error) { return as.OpenSessionSecret("user-1", "idp-access-token") },
+		"empty":         func() (string, error) { return as.OpenSessionSecret("user-1", "") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := open(); !errors.Is(err, ErrSessionSecretInvalid) {
+				t.Errorf("err = %v, want ErrSessionSecretInvalid", err)
+			}
+		})
+	}
+}
+
+func TestSessionEncryptionKey(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+
+	// Açık key verildiğinde client secret değişse de aynı key kullanılır
+	a := newTestAuthService(t, &config.ZitadelConfig{ClientSecret: "secret-a", SessionEncryptionKey: key}, &config.JWTConfig{})
+	b := newTestAuthService(t, &config.ZitadelConfig{ClientSecret: "secret-b", SessionEncryptionKey: key}, &config.JWTConfig{})
+
+	sealed, _ := a.SealSessionSecret("user-1", "idp-access-token")
+	if opened, err := b.OpenSessionSecret("user-1", sealed); err != nil || opened != "idp-access-token" {
+		t.Errorf("OpenSessionSecret with shared key = %q, %v", opened, err)
+	}
+
+	for _, invalid := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewAuthService(&config.ZitadelConfig{SessionEncryptionKey: invalid}, &config.JWTConfig{}, zap.NewNop()); err == nil {
+			t.Errorf("SessionEncryptionKey %q accepted", invalid)
+		}
+	}
+}
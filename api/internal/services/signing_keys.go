@@ -0,0 +1,202 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fiber-app/pkg/config"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+)
+
+// Key dosyası yokken üretilen geçici RSA signing key boyutu
+const signingKeyBits = 2048
+
+// JWK - RFC 7517 JSON Web Key (sadece RSA public key alanları)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSet - RFC 7517 JWK Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// signingKey - Uygulamanın kendi JWT'lerini imzaladığı RSA key
+type signingKey struct {
+	kid        string
+	privateKey *rsa.PrivateKey
+}
+
+// newSigningKey - Yeni RSA signing key üretir
+func newSigningKey() (*signingKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return &signingKey{
+		kid:        jwkThumbprint(&privateKey.PublicKey),
+		privateKey: privateKey,
+	}, nil
+}
+
+// loadSigningKey - PEM dosyasından (PKCS#1 veya PKCS#8) RSA private key yükler
+func loadSigningKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+
+	var privateKey *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if parseErr != nil {
+			err = parseErr
+			break
+		}
+		var ok bool
+		if privateKey, ok = parsed.(*rsa.PrivateKey); !ok {
+			err = fmt.Errorf("not an RSA key")
+		}
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	return &signingKey{
+		kid:        jwkThumbprint(&privateKey.PublicKey),
+		privateKey: privateKey,
+	}, nil
+}
+
+// jwk - Key'in public kısmını JWK formatında döner
+func (sk *signingKey) jwk() JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: sk.kid,
+		N:   base64.RawURLEncoding.EncodeToString(sk.privateKey.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(sk.privateKey.PublicKey.E)).Bytes()),
+	}
+}
+
+// jwkThumbprint - RFC 7638 JWK thumbprint, kid olarak kullanılır
+func jwkThumbprint(publicKey *rsa.PublicKey) string {
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	n := base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n)))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// signingKeyManager - Aktif signing key ve doğrulama için tutulan önceki key'ler
+type signingKeyManager struct {
+	mu       sync.RWMutex
+	active   *signingKey
+	previous []*signingKey
+}
+
+// newSigningKeyManager - Key'leri config'ten yükler, key dosyası yoksa geçici key üretir
+func newSigningKeyManager(cfg *config.JWTConfig) (*signingKeyManager, error) {
+	if cfg.SigningKeyFile == "" {
+		active, err := newSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		return &signingKeyManager{active: active}, nil
+	}
+
+	active, previous, err := loadSigningKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &signingKeyManager{active: active, previous: previous}, nil
+}
+
+// loadSigningKeys - Aktif ve önceki key'leri config'teki dosyalardan okur
+func loadSigningKeys(cfg *config.JWTConfig) (*signingKey, []*signingKey, error) {
+	active, err := loadSigningKey(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var previous []*signingKey
+	for _, path := range cfg.PreviousKeyFiles {
+		key, err := loadSigningKey(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		previous = append(previous, key)
+	}
+
+	return active, previous, nil
+}
+
+// current - Yeni token'ların imzalandığı key
+func (m *signingKeyManager) current() *signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active
+}
+
+// publicKey - kid'e ait public key (aktif veya önceki key'lerden)
+func (m *signingKeyManager) publicKey(kid string) (*rsa.PublicKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.active.kid == kid {
+		return &m.active.privateKey.PublicKey, true
+	}
+	for _, key := range m.previous {
+		if key.kid == kid {
+			return &key.privateKey.PublicKey, true
+		}
+	}
+	return nil, false
+}
+
+// replace - Key setini dosyalardan yeniden okunan key'lerle değiştirir, eski aktif key'i döner
+func (m *signingKeyManager) replace(active *signingKey, previous []*signingKey) *signingKey {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.active
+	m.active = active
+	m.previous = previous
+
+	return old
+}
+
+// jwks - Aktif ve önceki key'lerin public kısımları
+func (m *signingKeyManager) jwks() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []JWK{m.active.jwk()}
+	for _, key := range m.previous {
+		keys = append(keys, key.jwk())
+	}
+	return JWKSet{Keys: keys}
+}
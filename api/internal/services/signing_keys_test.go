@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fiber-app/pkg/config"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// mustSigningKey - Test için yeni RSA signing key
+func mustSigningKey(t *testing.T) *signingKey {
+	t.Helper()
+
+	key, err := newSigningKey()
+	if err != nil {
+		t.Fatalf("newSigningKey: %v", err)
+	}
+	return key
+}
+
+// writeKeyFile - Key'i verilen PEM tipinde geçici dosyaya yazar
+func writeKeyFile(t *testing.T, key *rsa.PrivateKey, pemType string) string {
+	t.Helper()
+
+	return writeKeyTo(t, filepath.Join(t.TempDir(), "signing.pem"), key, pemType)
+}
+
+// writeKeyTo - Key'i verilen yola yazar; reload testlerinde aynı dosyanın içeriği değiştirilir
+func writeKeyTo(t *testing.T, path string, key *rsa.PrivateKey, pemType string) string {
+	t.Helper()
+
+	var der []byte
+	switch pemType {
+	case "RSA PRIVATE KEY":
+		der = x509.MarshalPKCS1PrivateKey(key)
+	case "PRIVATE KEY":
+		var err error
+		if der, err = x509.MarshalPKCS8PrivateKey(key); err != nil {
+			t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+	return path
+}
+
+func TestLoadSigningKeyFormats(t *testing.T) {
+	key := mustSigningKey(t)
+
+	for _, pemType := range []string{"RSA PRIVATE KEY", "PRIVATE KEY"} {
+		t.Run(pemType, func(t *testing.T) {
+			loaded, err := loadSigningKey(writeKeyFile(t, key.privateKey, pemType))
+			if err != nil {
+				t.Fatalf("loadSigningKey: %v", err)
+			}
+			if loaded.kid != key.kid {
+				t.Errorf("kid = %s, want %s", loaded.kid, key.kid)
+			}
+			if !loaded.privateKey.Equal(key.privateKey) {
+				t.Error("loaded key differs from written key")
+			}
+		})
+	}
+}
+
+func TestLoadSigningKeyRejectsInvalidInput(t *testing.T) {
+	dir := t.TempDir()
+
+	notPEM := filepath.Join(dir, "not.pem")
+	os.WriteFile(notPEM, []byte("not a key"), 0o600)
+
+	unsupported := filepath.Join(dir, "cert.pem")
+	os.WriteFile(unsupported, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), 0o600)
+
+	for name, path := range map[string]string{
+		"missing file":      filepath.Join(dir, "missing.pem"),
+		"not PEM":           notPEM,
+		"unsupported block": unsupported,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadSigningKey(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// keyFiles - Aktif ve önceki key'ler için sabit yollu dosyalar; rotasyon içerikleri değiştirerek yapılır
+type keyFiles struct {
+	active, previous string
+}
+
+func newKeyFiles(t *testing.T) keyFiles {
+	t.Helper()
+
+	dir := t.TempDir()
+	return keyFiles{active: filepath.Join(dir, "active.pem"), previous: filepath.Join(dir, "previous.pem")}
+}
+
+// rotate - Operatörün yaptığı gibi aktif key'i önceki dosyasına taşır, yeni key'i aktif dosyaya yazar
+func (kf keyFiles) rotate(t *testing.T, from, to *signingKey) {
+	t.Helper()
+
+	writeKeyTo(t, kf.previous, from.privateKey, "RSA PRIVATE KEY")
+	writeKeyTo(t, kf.active, to.privateKey, "RSA PRIVATE KEY")
+}
+
+func TestReloadSigningKeysFromFiles(t *testing.T) {
+	files := newKeyFiles(t)
+	first, second := mustSigningKey(t), mustSigningKey(t)
+	writeKeyTo(t, files.active, first.privateKey, "RSA PRIVATE KEY")
+	writeKeyTo(t, files.previous, first.privateKey, "RSA PRIVATE KEY")
+
+	jwtCfg := &config.JWTConfig{SigningKeyFile: files.active, PreviousKeyFiles: []string{files.previous}}
+	replicaA := newTestAuthService(t, &config.ZitadelConfig{}, jwtCfg)
+	replicaB := newTestAuthService(t, &config.ZitadelConfig{}, jwtCfg)
+
+	files.rotate(t, first, second)
+	for name, as := range map[string]*AuthService{"replica A": replicaA, "replica B": replicaB} {
+		kid, err := as.ReloadSigningKeys()
+		if err != nil {
+			t.Fatalf("%s: ReloadSigningKeys: %v", name, err)
+		}
+		// Rastgele key yerine dosyadaki key kullanılır; tüm replikalar aynı kid'e geçer
+		if kid != second.kid {
+			t.Errorf("%s: kid = %s, want %s from the key file", name, kid, second.kid)
+		}
+		if _, ok := as.signingKeys.publicKey(first.kid); !ok {
+			t.Errorf("%s: previous key must stay verifiable after reload", name)
+		}
+	}
+
+	token, err := replicaA.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+	if _, err := replicaB.ValidateToken(context.Background(), token); err != nil {
+		t.Errorf("token from replica A rejected by replica B: %v", err)
+	}
+}
+
+func TestReloadSigningKeysKeepsKeysOnError(t *testing.T) {
+	files := newKeyFiles(t)
+	key := mustSigningKey(t)
+	writeKeyTo(t, files.active, key.privateKey, "RSA PRIVATE KEY")
+
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{SigningKeyFile: files.active})
+
+	os.WriteFile(files.active, []byte("not a key"), 0o600)
+	if _, err := as.ReloadSigningKeys(); err == nil {
+		t.Fatal("expected an error for an unreadable key file")
+	}
+	if as.signingKeys.current().kid != key.kid {
+		t.Error("failed reload must keep the current signing key")
+	}
+}
+
+func TestReloadSigningKeysRequiresKeyFile(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{})
+	kid := as.signingKeys.current().kid
+
+	if _, err := as.ReloadSigningKeys(); !errors.Is(err, ErrNoSigningKeyFile) {
+		t.Fatalf("err = %v, want ErrNoSigningKeyFile", err)
+	}
+	// Geçici key'le çalışan süreç SIGHUP'ta yeni rastgele key üretmez
+	if as.signingKeys.current().kid != kid {
+		t.Error("reload without a key file must not change the signing key")
+	}
+}
+
+func TestRequireSigningKeyFile(t *testing.T) {
+	_, err := NewAuthService(&config.ZitadelConfig{}, &config.JWTConfig{RequireSigningKeyFile: true}, zap.NewNop())
+	if err == nil {
+		t.Fatal("NewAuthService must refuse to start without a signing key file")
+	}
+
+	path := writeKeyFile(t, mustSigningKey(t).privateKey, "RSA PRIVATE KEY")
+	newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{RequireSigningKeyFile: true, SigningKeyFile: path})
+}
+
+// publishedKey - JWKS'teki kid'e ait JWK'yı RSA public key'e çevirir
+func publishedKey(t *testing.T, set JWKSet, kid string) *rsa.PublicKey {
+	t.Helper()
+
+	for _, jwk := range set.Keys {
+		if jwk.Kid != kid {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			t.Fatalf("decode n: %v", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			t.Fatalf("decode e: %v", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	t.Fatalf("kid %s not published in JWKS", kid)
+	return nil
+}
+
+func TestIssuedTokenVerifiesAgainstPublishedKey(t *testing.T) {
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{Audience: "fiber-app-test"})
+
+	tokenString, err := as.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+
+	// Partner servisin yapacağı gibi sadece JWKS ile doğrula
+	var claims TokenClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return publishedKey(t, as.JWKS(), kid), nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil || !token.Valid {
+		t.Fatalf("token does not verify against JWKS: %v", err)
+	}
+
+	if claims.Issuer != "fiber-app-test" || len(claims.Audience) != 1 || claims.Audience[0] != "fiber-app-test" {
+		t.Errorf("iss/aud = %q/%v", claims.Issuer, claims.Audience)
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		t.Error("jti and exp must be set")
+	}
+}
+
+func TestTokenSignedBeforeRotationStillValidates(t *testing.T) {
+	files := newKeyFiles(t)
+	first, second := mustSigningKey(t), mustSigningKey(t)
+	writeKeyTo(t, files.active, first.privateKey, "RSA PRIVATE KEY")
+	writeKeyTo(t, files.previous, first.privateKey, "RSA PRIVATE KEY")
+
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{
+		SigningKeyFile:   files.active,
+		PreviousKeyFiles: []string{files.previous},
+	})
+
+	before, err := as.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+
+	files.rotate(t, first, second)
+	kid, err := as.ReloadSigningKeys()
+	if err != nil {
+		t.Fatalf("ReloadSigningKeys: %v", err)
+	}
+
+	after, err := as.CreateJWTToken(&ZitadelUserInfo{Sub: "user-1"}, "")
+	if err != nil {
+		t.Fatalf("CreateJWTToken: %v", err)
+	}
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(after, &TokenClaims{})
+	if parsed.Header["kid"] != kid {
+		t.Errorf("new token kid = %v, want reloaded kid %s", parsed.Header["kid"], kid)
+	}
+
+	for name, token := range map[string]string{"before rotation": before, "after rotation": after} {
+		if _, err := as.ValidateToken(context.Background(), token); err != nil {
+			t.Errorf("%s: ValidateToken: %v", name, err)
+		}
+	}
+}
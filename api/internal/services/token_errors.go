@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenFailureReason - Token validation hatasının kategorisi
+type TokenFailureReason string
+
+const (
+	TokenFailureExpired       TokenFailureReason = "expired"
+	TokenFailureNotYetValid   TokenFailureReason = "not_yet_valid"
+	TokenFailureIssuedFuture  TokenFailureReason = "issued_in_future"
+	TokenFailureBadSignature  TokenFailureReason = "bad_signature"
+	TokenFailureWrongIssuer   TokenFailureReason = "wrong_issuer"
+	TokenFailureWrongAudience TokenFailureReason = "wrong_audience"
+	TokenFailureUnknownKeyID  TokenFailureReason = "unknown_kid"
+	TokenFailureMalformed     TokenFailureReason = "malformed"
+	TokenFailureInactive      TokenFailureReason = "inactive"
+	TokenFailureOpaque        TokenFailureReason = "opaque"
+	TokenFailureInvalid       TokenFailureReason = "invalid"
+)
+
+// ErrUnknownKeyID - Token'ın kid header'ı bilinen bir key ile eşleşmiyor
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// ErrOpaqueToken - Token JWT değil (opaque access token); lokal doğrulama yerine introspection gerekir
+var ErrOpaqueToken = errors.New("token is opaque, not a JWT; use TOKEN_VALIDATION_MODE=introspection for opaque access tokens")
+
+// TokenValidationError - Kategorize edilmiş token validation hatası
+type TokenValidationError struct {
+	Reason TokenFailureReason
+	Err    error
+	// Sadece süresi dolmuş (imzası ve diğer claim'leri geçerli) token'larda dolu
+	ExpiredClaims *TokenClaims
+}
+
+func (e *TokenValidationError) Error() string {
+	return fmt.Sprintf("token validation failed (%s): %v", e.Reason, e.Err)
+}
+
+func (e *TokenValidationError) Unwrap() error {
+	return e.Err
+}
+
+// classifyTokenError - jwt hatasını TokenFailureReason'a çevirir
+func classifyTokenError(err error) TokenFailureReason {
+	switch {
+	case errors.Is(err, ErrUnknownKeyID):
+		return TokenFailureUnknownKeyID
+	case errors.Is(err, ErrTokenInactive):
+		return TokenFailureInactive
+	case errors.Is(err, ErrOpaqueToken):
+		return TokenFailureOpaque
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return TokenFailureMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return TokenFailureBadSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return TokenFailureExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return TokenFailureNotYetValid
+	case errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return TokenFailureIssuedFuture
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return TokenFailureWrongIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return TokenFailureWrongAudience
+	default:
+		return TokenFailureInvalid
+	}
+}
+
+// onlyExpired - Token'ın tek sorunu süresinin dolmuş olması mı (imza, issuer, audience, nbf, iat geçerli)
+func onlyExpired(err error) bool {
+	if !errors.Is(err, jwt.ErrTokenExpired) {
+		return false
+	}
+
+	for _, other := range []error{
+		jwt.ErrTokenMalformed,
+		jwt.ErrTokenSignatureInvalid,
+		jwt.ErrTokenNotValidYet,
+		jwt.ErrTokenUsedBeforeIssued,
+		jwt.ErrTokenInvalidIssuer,
+		jwt.ErrTokenInvalidAudience,
+		ErrUnknownKeyID,
+	} {
+		if errors.Is(err, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsClockSkew - nbf/iat gelecekte: genelde issuer ile yerel saat arasındaki kaymayı gösterir
+// (expired ise token'ın gerçekten eskidiğini gösterir)
+func (r TokenFailureReason) IsClockSkew() bool {
+	return r == TokenFailureNotYetValid || r == TokenFailureIssuedFuture
+}
+
+// tokenFailureCounter - Reason bazında hata sayacı
+type tokenFailureCounter struct {
+	mu     sync.Mutex
+	counts map[TokenFailureReason]int64
+}
+
+func newTokenFailureCounter() *tokenFailureCounter {
+	return &tokenFailureCounter{
+		counts: make(map[TokenFailureReason]int64),
+	}
+}
+
+func (tc *tokenFailureCounter) inc(reason TokenFailureReason) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.counts[reason]++
+}
+
+func (tc *tokenFailureCounter) snapshot() map[string]int64 {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	result := make(map[string]int64, len(tc.counts))
+	for reason, count := range tc.counts {
+		result[string(reason)] = count
+	}
+	return result
+}
+
+// isJWT - Token JWS compact formatında mı (header.payload.signature)
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
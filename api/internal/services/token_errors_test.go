@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fiber-app/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// validClaims - AuthService'in kabul edeceği, şu an geçerli claim'ler
+func validClaims(as *AuthService) TokenClaims {
+	now := time.Now()
+	return TokenClaims{
+		Sub: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    as.jwtConfig.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+// signClaims - Claim'leri verilen kid ve key ile RS256 imzalar
+func signClaims(t *testing.T, claims TokenClaims, kid string, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+// observeTokenFailures - Token hata loglarını yakalayan AuthService
+func observeTokenFailures(t *testing.T, logFailures bool) (*AuthService, *observer.ObservedLogs) {
+	t.Helper()
+
+	as := newTestAuthService(t, &config.ZitadelConfig{LogTokenFailures: logFailures}, &config.JWTConfig{
+		Audience:     "fiber-app",
+		ClockSkew:    30 * time.Second,
+		MaxClockSkew: time.Minute,
+	})
+	core, logs := observer.New(zapcore.WarnLevel)
+	as.logger = zap.New(core)
+	return as, logs
+}
+
+func TestValidateTokenFailureReasons(t *testing.T) {
+	as, _ := observeTokenFailures(t, false)
+	key := as.signingKeys.current()
+	other := mustSigningKey(t)
+
+	sign := func(mutate func(*TokenClaims)) string {
+		claims := validClaims(as)
+		claims.Audience = jwt.ClaimStrings{"fiber-app"}
+		mutate(&claims)
+		return signClaims(t, claims, key.kid, key.privateKey)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  TokenFailureReason
+	}{
+		{"opaque", "opaque-access-token", TokenFailureOpaque},
+		{"malformed", "not.a.jwt", TokenFailureMalformed},
+		{"bad signature", signClaims(t, validClaims(as), key.kid, other.privateKey), TokenFailureBadSignature},
+		{"unknown kid", signClaims(t, validClaims(as), other.kid, other.privateKey), TokenFailureUnknownKeyID},
+		{"expired", sign(func(c *TokenClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+			c.NotBefore = c.IssuedAt
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		}), TokenFailureExpired},
+		{"not yet valid", sign(func(c *TokenClaims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(10 * time.Minute))
+		}), TokenFailureNotYetValid},
+		{"issued in future", sign(func(c *TokenClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(10 * time.Minute))
+		}), TokenFailureIssuedFuture},
+		{"wrong issuer", sign(func(c *TokenClaims) { c.Issuer = "someone-else" }), TokenFailureWrongIssuer},
+		{"wrong audience", sign(func(c *TokenClaims) { c.Audience = jwt.ClaimStrings{"other-service"} }), TokenFailureWrongAudience},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := as.ValidateToken(context.Background(), tt.token)
+
+			var validationErr *TokenValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want *TokenValidationError", err)
+			}
+			if validationErr.Reason != tt.want {
+				t.Errorf("reason = %q, want %q (%v)", validationErr.Reason, tt.want, err)
+			}
+		})
+	}
+
+	stats := as.TokenFailureStats()
+	for _, tt := range tests {
+		if stats[string(tt.want)] != 1 {
+			t.Errorf("stats[%q] = %d, want 1", tt.want, stats[string(tt.want)])
+		}
+	}
+}
+
+func TestValidateTokenAcceptsValidToken(t *testing.T) {
+	as, logs := observeTokenFailures(t, true)
+	key := as.signingKeys.current()
+
+	claims := validClaims(as)
+	claims.Audience = jwt.ClaimStrings{"fiber-app"}
+	got, err := as.ValidateToken(context.Background(), signClaims(t, claims, key.kid, key.privateKey))
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if got.Sub != "user-1" {
+		t.Errorf("sub = %q, want user-1", got.Sub)
+	}
+	if len(as.TokenFailureStats()) != 0 || logs.Len() != 0 {
+		t.Errorf("valid token recorded a failure: %v", as.TokenFailureStats())
+	}
+}
+
+func TestTokenFailureLogging(t *testing.T) {
+	t.Run("logs reason", func(t *testing.T) {
+		as, logs := observeTokenFailures(t, true)
+		as.ValidateToken(context.Background(), "opaque-access-token")
+
+		entries := logs.FilterMessage("Token validation failed").All()
+		if len(entries) != 1 {
+			t.Fatalf("logged %d failures, want 1", len(entries))
+		}
+		fields := entries[0].ContextMap()
+		if fields["reason"] != string(TokenFailureOpaque) {
+			t.Errorf("reason = %v, want %q", fields["reason"], TokenFailureOpaque)
+		}
+		if _, ok := fields["error"]; !ok {
+			t.Error("error field missing")
+		}
+		if _, ok := fields["clock_skew_suspected"]; ok {
+			t.Error("clock_skew_suspected set for a non clock-skew failure")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		as, logs := observeTokenFailures(t, false)
+		as.ValidateToken(context.Background(), "opaque-access-token")
+
+		if logs.Len() != 0 {
+			t.Errorf("logged %d entries with LogTokenFailures disabled", logs.Len())
+		}
+		// Log kapalıyken de sayaç tutulur
+		if got := as.TokenFailureStats()[string(TokenFailureOpaque)]; got != 1 {
+			t.Errorf("stats[opaque] = %d, want 1", got)
+		}
+	})
+}
+
+func TestClockSkewFailuresAreLoggedSeparately(t *testing.T) {
+	as, logs := observeTokenFailures(t, true)
+	key := as.signingKeys.current()
+
+	tests := []struct {
+		name          string
+		mutate        func(*TokenClaims)
+		wantReason    TokenFailureReason
+		wantClockSkew bool
+	}{
+		{"nbf in future", func(c *TokenClaims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(5 * time.Minute))
+		}, TokenFailureNotYetValid, true},
+		{"iat in future", func(c *TokenClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(5 * time.Minute))
+		}, TokenFailureIssuedFuture, true},
+		{"exp in past", func(c *TokenClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-5 * time.Minute))
+		}, TokenFailureExpired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims(as)
+			claims.Audience = jwt.ClaimStrings{"fiber-app"}
+			tt.mutate(&claims)
+
+			before := logs.Len()
+			as.ValidateToken(context.Background(), signClaims(t, claims, key.kid, key.privateKey))
+
+			entries := logs.All()[before:]
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["reason"] != string(tt.wantReason) {
+				t.Errorf("reason = %v, want %q", fields["reason"], tt.wantReason)
+			}
+			if _, suspected := fields["clock_skew_suspected"]; suspected != tt.wantClockSkew {
+				t.Errorf("clock_skew_suspected present = %v, want %v", suspected, tt.wantClockSkew)
+			}
+			if tt.wantReason.IsClockSkew() != tt.wantClockSkew {
+				t.Errorf("IsClockSkew() = %v, want %v", !tt.wantClockSkew, tt.wantClockSkew)
+			}
+		})
+	}
+}
+
+func TestTimeClaimsWithinClockSkewAreAccepted(t *testing.T) {
+	// observeTokenFailures 30s clock skew ile çalışır
+	as, _ := observeTokenFailures(t, false)
+	key := as.signingKeys.current()
+
+	claims := validClaims(as)
+	claims.Audience = jwt.ClaimStrings{"fiber-app"}
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(10 * time.Second))
+	claims.NotBefore = claims.IssuedAt
+
+	if _, err := as.ValidateToken(context.Background(), signClaims(t, claims, key.kid, key.privateKey)); err != nil {
+		t.Errorf("token issued 10s ahead rejected with 30s skew: %v", err)
+	}
+}
+
+func TestEffectiveClockSkew(t *testing.T) {
+	tests := []struct {
+		name     string
+		skew     time.Duration
+		want     time.Duration
+		wantWarn bool
+	}{
+		{"zero", 0, 0, false},
+		{"negative", -time.Minute, 0, false},
+		{"within cap", 30 * time.Second, 30 * time.Second, false},
+		{"at cap", 5 * time.Minute, 5 * time.Minute, false},
+		{"above cap", 24 * time.Hour, 5 * time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			got := effectiveClockSkew(&config.JWTConfig{ClockSkew: tt.skew, MaxClockSkew: 5 * time.Minute}, zap.New(core))
+
+			if got != tt.want {
+				t.Errorf("skew = %v, want %v", got, tt.want)
+			}
+			if warned := logs.Len() > 0; warned != tt.wantWarn {
+				t.Errorf("warned = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestClockSkewCapAppliesToValidation(t *testing.T) {
+	// Yanlışlıkla 24 saat girilen tolerans 1 dakikaya kırpılır
+	as := newTestAuthService(t, &config.ZitadelConfig{}, &config.JWTConfig{
+		ClockSkew:    24 * time.Hour,
+		MaxClockSkew: time.Minute,
+	})
+	key := as.signingKeys.current()
+
+	expiredAgo := func(d time.Duration) string {
+		claims := validClaims(as)
+		claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		claims.NotBefore = claims.IssuedAt
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-d))
+		return signClaims(t, claims, key.kid, key.privateKey)
+	}
+
+	if _, err := as.ValidateToken(context.Background(), expiredAgo(30*time.Second)); err != nil {
+		t.Errorf("token expired 30s ago rejected with 1m cap: %v", err)
+	}
+	_, err := as.ValidateToken(context.Background(), expiredAgo(10*time.Minute))
+	var validationErr *TokenValidationError
+	if !errors.As(err, &validationErr) || validationErr.Reason != TokenFailureExpired {
+		t.Errorf("token expired 10m ago: err = %v, want reason %q", err, TokenFailureExpired)
+	}
+}
+
+func TestOpaqueTokenError(t *testing.T) {
+	as, _ := observeTokenFailures(t, false)
+
+	for _, token := range []string{"opaque-access-token", "a.b", "a.b.c.d"} {
+		if _, err := as.ValidateToken(context.Background(), token); !errors.Is(err, ErrOpaqueToken) {
+			t.Errorf("ValidateToken(%q) err = %v, want ErrOpaqueToken", token, err)
+		}
+	}
+	// JWT biçimindeki bozuk token opaque sayılmaz
+	if _, err := as.ValidateToken(context.Background(), "not.a.jwt"); errors.Is(err, ErrOpaqueToken) {
+		t.Error("malformed JWT reported as opaque")
+	}
+}
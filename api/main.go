@@ -17,7 +17,11 @@ import (
 	"fiber-app/pkg/cache"
 	"fiber-app/pkg/config"
 	"fiber-app/pkg/database"
+	"fiber-app/pkg/i18n"
+	"fiber-app/pkg/tracing"
 	"fiber-app/router"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -25,7 +29,6 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -48,6 +51,13 @@ func main() {
 	}
 	defer zapLogger.Sync()
 
+	// "migrate" modu: sadece migration çalıştır ve çık (HTTP server başlatılmaz)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrations(cfg)
+		zapLogger.Sync()
+		os.Exit(code)
+	}
+
 	// Database bağlantısı
 	if err := database.Connect(cfg, zapLogger); err != nil {
 		log.Fatal("Database bağlantısı başarısız:", err)
@@ -58,6 +68,11 @@ func main() {
 		zapLogger.Fatal("Database migration başarısız", zap.Error(err))
 	}
 
+	// Kullanıcı aramasında benzerlik sıralaması için pg_trgm
+	if err := database.EnableTrigramSearch(); err != nil {
+		zapLogger.Warn("pg_trgm etkinleştirilemedi, arama sıralamasız ILIKE ile yapılacak", zap.Error(err))
+	}
+
 	// Default rolleri oluştur
 	if err := database.SeedDefaultRoles(); err != nil {
 		zapLogger.Fatal("Default roles oluşturulamadı", zap.Error(err))
@@ -75,9 +90,21 @@ func main() {
 
 	// Auth service'i başlat
 	if cfg.Zitadel.ClientID != "" && cfg.Zitadel.ClientSecret != "" {
-		authService := services.NewAuthService(&cfg.Zitadel, zapLogger)
+		authService, err := services.NewAuthService(&cfg.Zitadel, &cfg.JWT, zapLogger)
+		if err != nil {
+			zapLogger.Fatal("Auth service başlatılamadı", zap.Error(err))
+		}
 		handlers.SetAuthService(authService)
 
+		// Signing key rotasyonu HTTP'ye açılmaz; operatör key dosyalarını güncelleyip SIGHUP gönderir (kill -HUP <pid>)
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		middleware.SafeGo(zapLogger, "signing-key-reload", func() {
+			for range reload {
+				authService.ReloadSigningKeys()
+			}
+		})
+
 		// Auth middleware'i başlat
 		authMiddleware := middleware.NewAuthMiddleware(authService, zapLogger)
 		_ = authMiddleware // Şimdilik kullanılmıyor, route'larda kullanılacak
@@ -97,24 +124,25 @@ func main() {
 
 	// Handler'lara logger'ı set et
 	handlers.SetLogger(zapLogger)
+	handlers.SetFieldLimits(cfg.Limits)
 
 	// Middleware'ler
-	app.Use(recover.New())
+	app.Use(middleware.Recover(zapLogger))
 	app.Use(logger.New())
 	app.Use(traceIDMiddleware)
 
 	// Routes
-	router.SetupRoutes(app)
+	router.SetupRoutes(app, cfg)
 
 	// Graceful shutdown
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
+	middleware.SafeGo(zapLogger, "http-server", func() {
 		if err := app.Listen(":" + cfg.Port); err != nil {
 			zapLogger.Fatal("Server başlatılamadı", zap.Error(err))
 		}
-	}()
+	})
 
 	zapLogger.Info("Server başlatıldı",
 		zap.String("port", cfg.Port),
@@ -126,10 +154,51 @@ func main() {
 	app.Shutdown()
 }
 
+// runMigrations - Database'e bağlanıp migration ve seed işlemlerini çalıştırır, exit code döner
+func runMigrations(cfg *config.Config) int {
+	if err := database.Connect(cfg, zapLogger); err != nil {
+		fmt.Fprintln(os.Stderr, "Database bağlantısı başarısız:", err)
+		return 1
+	}
+
+	return migrate(os.Stdout, os.Stderr)
+}
+
+// migrate - Bağlı database üzerinde migration ve seed'i çalıştırır, tablo durumunu out'a yazar
+func migrate(out, errOut io.Writer) int {
+	if err := database.Migrate(); err != nil {
+		fmt.Fprintln(errOut, "Database migration başarısız:", err)
+		return 1
+	}
+
+	if err := database.EnableTrigramSearch(); err != nil {
+		fmt.Fprintln(errOut, "pg_trgm etkinleştirilemedi (arama sıralamasız çalışır):", err)
+	}
+
+	if err := database.SeedDefaultRoles(); err != nil {
+		fmt.Fprintln(errOut, "Default roles oluşturulamadı:", err)
+		return 1
+	}
+
+	status, err := database.Status()
+	if err != nil {
+		fmt.Fprintln(errOut, "Migration durumu alınamadı:", err)
+		return 1
+	}
+
+	fmt.Fprintln(out, "Migration tamamlandı")
+	for _, table := range status {
+		fmt.Fprintf(out, "  %-10s exists=%t rows=%d\n", table.Name, table.Exists, table.Rows)
+	}
+
+	return 0
+}
+
 // Trace ID middleware - her request için unique trace_id oluşturur
 func traceIDMiddleware(c *fiber.Ctx) error {
 	traceID := uuid.New().String()
 	c.Locals("trace_id", traceID)
+	c.SetUserContext(tracing.WithTraceID(c.UserContext(), traceID))
 	c.Set("X-Trace-ID", traceID)
 
 	zapLogger.Info("Request başladı",
@@ -152,10 +221,7 @@ func errorHandler(c *fiber.Ctx, err error) error {
 		zap.String("path", c.Path()),
 	)
 
-	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		"error":    "Internal Server Error",
-		"trace_id": traceID,
-	})
+	return errorResponse(c, fiber.StatusInternalServerError, i18n.CodeInternalError)
 }
 
 // Trace ID helper
@@ -165,3 +231,12 @@ func getTraceID(c *fiber.Ctx) string {
 	}
 	return "unknown"
 }
+
+// errorResponse - İsteğin diline göre lokalize edilmiş hata mesajı ve kodu ile JSON hata döner
+func errorResponse(c *fiber.Ctx, status int, code i18n.Code) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error":    i18n.T(c, code),
+		"code":     code,
+		"trace_id": getTraceID(c),
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fiber-app/pkg/config"
+	"fiber-app/pkg/database/dbtest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// migrationDB - Tabloları CREATE TABLE çalıştıktan sonra var sayan sahte DB
+func migrationDB(t *testing.T) *dbtest.DB {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	db.HandleQuery("information_schema.tables", func(_ string, args []driver.Value) dbtest.Result {
+		var count int64
+		for _, arg := range args {
+			if table, ok := arg.(string); ok && len(db.Statements(`CREATE TABLE "`+table+`"`)) > 0 {
+				count = 1
+			}
+		}
+		return dbtest.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{count}}}
+	})
+	db.Rows(`count(*) FROM "`, []string{"count"}, []driver.Value{int64(3)})
+	return db
+}
+
+func TestMigratePrintsStatus(t *testing.T) {
+	db := migrationDB(t)
+
+	var out, errOut bytes.Buffer
+	if code := migrate(&out, &errOut); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut.String())
+	}
+
+	for _, table := range []string{"roles", "users", "role_histories"} {
+		if len(db.Statements(`CREATE TABLE "`+table+`"`)) != 1 {
+			t.Errorf("table %s was not created", table)
+		}
+		if !strings.Contains(out.String(), table) || !strings.Contains(out.String(), "exists=true rows=3") {
+			t.Errorf("status output missing %s:\n%s", table, out.String())
+		}
+	}
+	if !strings.HasPrefix(out.String(), "Migration tamamlandı") {
+		t.Errorf("output = %q", out.String())
+	}
+	if seeded := db.Statements(`INSERT INTO "roles"`); len(seeded) != 3 {
+		t.Errorf("seeded %d roles, want 3", len(seeded))
+	}
+}
+
+func TestMigrateFailureExitsNonZero(t *testing.T) {
+	tests := []struct {
+		name    string
+		marker  string
+		wantErr string
+	}{
+		{"migration", `CREATE TABLE "users"`, "Database migration başarısız"},
+		{"seed", `INSERT INTO "roles"`, "Default roles oluşturulamadı"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := migrationDB(t)
+			db.Fail(tt.marker, errors.New("permission denied"))
+
+			var out, errOut bytes.Buffer
+			if code := migrate(&out, &errOut); code != 1 {
+				t.Fatalf("exit code = %d, want 1", code)
+			}
+			if !strings.Contains(errOut.String(), tt.wantErr) || !strings.Contains(errOut.String(), "permission denied") {
+				t.Errorf("stderr = %q", errOut.String())
+			}
+			if strings.Contains(out.String(), "Migration tamamlandı") {
+				t.Error("failed migration reported success")
+			}
+		})
+	}
+}
+
+func TestRunMigrationsWithoutDatabase(t *testing.T) {
+	previous := zapLogger
+	zapLogger = zap.NewNop()
+	t.Cleanup(func() { zapLogger = previous })
+
+	// Bağlantı kurulamazsa server başlatılmadan hata koduyla çıkılır
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Host:    "127.0.0.1",
+		Port:    "1",
+		User:    "postgres",
+		DBName:  "fiber_app",
+		SSLMode: "disable",
+	}}
+	if code := runMigrations(cfg); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
@@ -0,0 +1,859 @@
+package cachetest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Uygulamanın Lua script'lerini olduğu gibi çalıştırmak için küçük bir Lua alt kümesi yorumlayıcısı.
+// Desteklenenler: local/atama, if/elseif/else, return, tablo kurucuları, indeksleme, fonksiyon çağrıları,
+// aritmetik, karşılaştırma, and/or/not, .. ve tonumber, tostring, math.floor, redis.call/pcall.
+// Script bu alt kümenin dışına çıkarsa EVAL parse hatası döner; test sessizce yanlış sonuç üretmez.
+
+// luaTable - Lua tablosu; script'ler sadece dizi kısmını ve sabit alanları kullanır
+type luaTable struct {
+	array  []interface{}
+	fields map[string]interface{}
+}
+
+// luaFunc - Go ile yazılmış Lua fonksiyonu
+type luaFunc func(args []interface{}) (interface{}, error)
+
+// luaError - Script'ten yükselen hata; EVAL bunu hata yanıtı olarak döner
+type luaError struct{ msg string }
+
+func (e *luaError) Error() string { return e.msg }
+
+// runLua - Script'i KEYS/ARGV ile çalıştırır, dönen değeri RESP yanıtına çevirir (mu tutulmalı)
+func (s *Server) runLua(body string, keys, argv []string) interface{} {
+	block, err := parseLua(body)
+	if err != nil {
+		return fmt.Errorf("ERR cachetest: %v", err)
+	}
+
+	env := &luaEnv{vars: map[string]interface{}{
+		"KEYS":     stringsTable(keys),
+		"ARGV":     stringsTable(argv),
+		"tonumber": luaFunc(luaToNumber),
+		"tostring": luaFunc(func(args []interface{}) (interface{}, error) { return luaToString(arg(args, 0)), nil }),
+		"math": &luaTable{fields: map[string]interface{}{
+			"floor": luaFunc(func(args []interface{}) (interface{}, error) {
+				n, err := luaNumber(arg(args, 0))
+				return math.Floor(n), err
+			}),
+		}},
+		"redis": &luaTable{fields: map[string]interface{}{
+			"call":  luaFunc(func(args []interface{}) (interface{}, error) { return s.luaCall(args, false) }),
+			"pcall": luaFunc(func(args []interface{}) (interface{}, error) { return s.luaCall(args, true) }),
+		}},
+	}}
+
+	result, _, err := block.exec(env)
+	if err != nil {
+		return errors.New("ERR " + err.Error())
+	}
+	return luaToRedis(result)
+}
+
+// luaCall - redis.call: argümanları string'e çevirip komutu aynı kilit altında çalıştırır
+func (s *Server) luaCall(args []interface{}, protected bool) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, &luaError{"wrong number of arguments for redis.call"}
+	}
+
+	cmd := make([]string, len(args))
+	for i, a := range args {
+		switch v := a.(type) {
+		case string:
+			cmd[i] = v
+		case float64:
+			cmd[i] = luaToString(v)
+		default:
+			return nil, &luaError{"redis.call arguments must be strings or integers"}
+		}
+	}
+
+	reply := s.exec(cmd)
+	if err, ok := reply.(error); ok {
+		if protected {
+			return &luaTable{fields: map[string]interface{}{"err": err.Error()}}, nil
+		}
+		return nil, &luaError{err.Error()}
+	}
+	return redisToLua(reply), nil
+}
+
+// redisToLua - Redis yanıtının Lua karşılığı (nil yanıt false olur)
+func redisToLua(reply interface{}) interface{} {
+	switch v := reply.(type) {
+	case nil:
+		return false
+	case status:
+		return &luaTable{fields: map[string]interface{}{"ok": string(v)}}
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case []interface{}:
+		t := &luaTable{}
+		for _, item := range v {
+			t.array = append(t.array, redisToLua(item))
+		}
+		return t
+	}
+	return reply
+}
+
+// luaToRedis - Script'in dönüş değerinin Redis karşılığı (sayılar tam sayıya kesilir, dizi ilk nil'de biter)
+func luaToRedis(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return nil
+	case float64:
+		return int64(v)
+	case *luaTable:
+		if msg, ok := v.fields["err"].(string); ok {
+			return errors.New(msg)
+		}
+		if msg, ok := v.fields["ok"].(string); ok {
+			return status(msg)
+		}
+		reply := []interface{}{}
+		for _, item := range v.array {
+			if item == nil {
+				break
+			}
+			reply = append(reply, luaToRedis(item))
+		}
+		return reply
+	}
+	return value
+}
+
+func stringsTable(values []string) *luaTable {
+	t := &luaTable{}
+	for _, v := range values {
+		t.array = append(t.array, v)
+	}
+	return t
+}
+
+func arg(args []interface{}, i int) interface{} {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+func luaToNumber(args []interface{}) (interface{}, error) {
+	switch v := arg(args, 0).(type) {
+	case float64:
+		return v, nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func luaNumber(value interface{}) (float64, error) {
+	if n, _ := luaToNumber([]interface{}{value}); n != nil {
+		return n.(float64), nil
+	}
+	return 0, &luaError{fmt.Sprintf("attempt to perform arithmetic on a %s value", luaType(value))}
+}
+
+func luaToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'g', 14, 64)
+	case string:
+		return v
+	}
+	return luaType(value)
+}
+
+func luaType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *luaTable:
+		return "table"
+	case luaFunc:
+		return "function"
+	}
+	return "userdata"
+}
+
+func luaTruthy(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return value != nil
+}
+
+// luaEnv - Değişken kapsamı; blok içindeki local'ler dış kapsamı gölgeler
+type luaEnv struct {
+	vars   map[string]interface{}
+	parent *luaEnv
+}
+
+func (e *luaEnv) lookup(name string) interface{} {
+	for env := e; env != nil; env = env.parent {
+		if v, ok := env.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (e *luaEnv) assign(name string, value interface{}) {
+	env := e
+	for ; env.parent != nil; env = env.parent {
+		if _, ok := env.vars[name]; ok {
+			break
+		}
+	}
+	env.vars[name] = value
+}
+
+// AST
+
+type luaExpr interface {
+	eval(env *luaEnv) (interface{}, error)
+}
+
+type luaStmt interface {
+	// exec - returned true ise script return ile bitmiştir
+	exec(env *luaEnv) (result interface{}, returned bool, err error)
+}
+
+type luaBlock []luaStmt
+
+func (b luaBlock) exec(env *luaEnv) (interface{}, bool, error) {
+	for _, stmt := range b {
+		result, returned, err := stmt.exec(env)
+		if err != nil || returned {
+			return result, returned, err
+		}
+	}
+	return nil, false, nil
+}
+
+type luaLocal struct {
+	name  string
+	value luaExpr
+}
+
+func (s *luaLocal) exec(env *luaEnv) (interface{}, bool, error) {
+	var value interface{}
+	if s.value != nil {
+		var err error
+		if value, err = s.value.eval(env); err != nil {
+			return nil, false, err
+		}
+	}
+	env.vars[s.name] = value
+	return nil, false, nil
+}
+
+type luaAssign struct {
+	name  string
+	value luaExpr
+}
+
+func (s *luaAssign) exec(env *luaEnv) (interface{}, bool, error) {
+	value, err := s.value.eval(env)
+	if err != nil {
+		return nil, false, err
+	}
+	env.assign(s.name, value)
+	return nil, false, nil
+}
+
+type luaExprStmt struct{ expr luaExpr }
+
+func (s *luaExprStmt) exec(env *luaEnv) (interface{}, bool, error) {
+	_, err := s.expr.eval(env)
+	return nil, false, err
+}
+
+type luaReturn struct{ value luaExpr }
+
+func (s *luaReturn) exec(env *luaEnv) (interface{}, bool, error) {
+	if s.value == nil {
+		return nil, true, nil
+	}
+	value, err := s.value.eval(env)
+	return value, true, err
+}
+
+type luaIf struct {
+	conds  []luaExpr
+	blocks []luaBlock
+	orElse luaBlock
+}
+
+func (s *luaIf) exec(env *luaEnv) (interface{}, bool, error) {
+	for i, cond := range s.conds {
+		value, err := cond.eval(env)
+		if err != nil {
+			return nil, false, err
+		}
+		if luaTruthy(value) {
+			return s.blocks[i].exec(&luaEnv{vars: map[string]interface{}{}, parent: env})
+		}
+	}
+	if s.orElse != nil {
+		return s.orElse.exec(&luaEnv{vars: map[string]interface{}{}, parent: env})
+	}
+	return nil, false, nil
+}
+
+type luaConst struct{ value interface{} }
+
+func (e *luaConst) eval(*luaEnv) (interface{}, error) { return e.value, nil }
+
+type luaName struct{ name string }
+
+func (e *luaName) eval(env *luaEnv) (interface{}, error) { return env.lookup(e.name), nil }
+
+type luaIndex struct {
+	target, key luaExpr
+}
+
+func (e *luaIndex) eval(env *luaEnv) (interface{}, error) {
+	target, err := e.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	table, ok := target.(*luaTable)
+	if !ok {
+		return nil, &luaError{fmt.Sprintf("attempt to index a %s value", luaType(target))}
+	}
+	switch k := key.(type) {
+	case float64:
+		if i := int(k); float64(i) == k && i >= 1 && i <= len(table.array) {
+			return table.array[i-1], nil
+		}
+	case string:
+		return table.fields[k], nil
+	}
+	return nil, nil
+}
+
+type luaCallExpr struct {
+	fn   luaExpr
+	args []luaExpr
+}
+
+func (e *luaCallExpr) eval(env *luaEnv) (interface{}, error) {
+	fn, err := e.fn.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	call, ok := fn.(luaFunc)
+	if !ok {
+		return nil, &luaError{fmt.Sprintf("attempt to call a %s value", luaType(fn))}
+	}
+
+	args := make([]interface{}, len(e.args))
+	for i, a := range e.args {
+		if args[i], err = a.eval(env); err != nil {
+			return nil, err
+		}
+	}
+	return call(args)
+}
+
+type luaTableExpr struct{ items []luaExpr }
+
+func (e *luaTableExpr) eval(env *luaEnv) (interface{}, error) {
+	t := &luaTable{}
+	for _, item := range e.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		t.array = append(t.array, value)
+	}
+	return t, nil
+}
+
+type luaUnary struct {
+	op      string
+	operand luaExpr
+}
+
+func (e *luaUnary) eval(env *luaEnv) (interface{}, error) {
+	value, err := e.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "not" {
+		return !luaTruthy(value), nil
+	}
+	n, err := luaNumber(value)
+	return -n, err
+}
+
+type luaBinary struct {
+	op          string
+	left, right luaExpr
+}
+
+func (e *luaBinary) eval(env *luaEnv) (interface{}, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// and/or kısa devre yapar ve operandın kendisini döner
+	switch e.op {
+	case "and":
+		if !luaTruthy(left) {
+			return left, nil
+		}
+		return e.right.eval(env)
+	case "or":
+		if luaTruthy(left) {
+			return left, nil
+		}
+		return e.right.eval(env)
+	}
+
+	right, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return left == right, nil
+	case "~=":
+		return left != right, nil
+	case "..":
+		return luaToString(left) + luaToString(right), nil
+	}
+
+	if ls, ok := left.(string); ok {
+		if rs, ok := right.(string); ok {
+			switch e.op {
+			case "<":
+				return ls < rs, nil
+			case "<=":
+				return ls <= rs, nil
+			case ">":
+				return ls > rs, nil
+			case ">=":
+				return ls >= rs, nil
+			}
+		}
+	}
+
+	l, err := luaNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := luaNumber(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	case "%":
+		return l - math.Floor(l/r)*r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	}
+	return nil, &luaError{"unsupported operator " + e.op}
+}
+
+// Parser
+
+type luaToken struct {
+	kind  string // "name", "number", "string", "op", "eof"
+	value string
+}
+
+var luaKeywords = map[string]bool{
+	"local": true, "if": true, "then": true, "elseif": true, "else": true, "end": true,
+	"return": true, "and": true, "or": true, "not": true, "nil": true, "true": true, "false": true,
+}
+
+func tokenizeLua(src string) ([]luaToken, error) {
+	var tokens []luaToken
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '-' && strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(rune(ch)):
+			i++
+		case ch == '_' || unicode.IsLetter(rune(ch)):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, luaToken{"name", src[start:i]})
+		case unicode.IsDigit(rune(ch)):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, luaToken{"number", src[start:i]})
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(src[i+1:], ch)
+			if end < 0 {
+				return nil, errors.New("unfinished string")
+			}
+			tokens = append(tokens, luaToken{"string", src[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			op := string(ch)
+			if i+1 < len(src) {
+				if two := src[i : i+2]; two == "==" || two == "~=" || two == "<=" || two == ">=" || two == ".." {
+					op = two
+				}
+			}
+			if !luaOperators[op] {
+				return nil, fmt.Errorf("unexpected character %q", ch)
+			}
+			tokens = append(tokens, luaToken{"op", op})
+			i += len(op)
+		}
+	}
+	return append(tokens, luaToken{kind: "eof"}), nil
+}
+
+var luaOperators = map[string]bool{
+	"==": true, "~=": true, "<=": true, ">=": true, "..": true, "<": true, ">": true, "=": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true,
+	"(": true, ")": true, "{": true, "}": true, "[": true, "]": true, ",": true, ".": true, ";": true,
+}
+
+type luaParser struct {
+	tokens []luaToken
+	pos    int
+}
+
+func parseLua(src string) (luaBlock, error) {
+	tokens, err := tokenizeLua(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &luaParser{tokens: tokens}
+	block, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if !p.at("eof", "") {
+		return nil, fmt.Errorf("unexpected %q", p.peek().value)
+	}
+	return block, nil
+}
+
+func (p *luaParser) peek() luaToken { return p.tokens[p.pos] }
+
+func (p *luaParser) next() luaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+// at - Sıradaki token verilen türde (ve value boş değilse o değerde) mi
+func (p *luaParser) at(kind, value string) bool {
+	tok := p.peek()
+	return tok.kind == kind && (value == "" || tok.value == value)
+}
+
+func (p *luaParser) keyword(value string) bool {
+	return p.at("name", value)
+}
+
+func (p *luaParser) expect(kind, value string) error {
+	if !p.at(kind, value) {
+		return fmt.Errorf("expected %q, got %q", value, p.peek().value)
+	}
+	p.next()
+	return nil
+}
+
+func (p *luaParser) block() (luaBlock, error) {
+	var block luaBlock
+	for !p.at("eof", "") && !p.keyword("end") && !p.keyword("else") && !p.keyword("elseif") {
+		if p.at("op", ";") {
+			p.next()
+			continue
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		block = append(block, stmt)
+	}
+	return block, nil
+}
+
+func (p *luaParser) statement() (luaStmt, error) {
+	switch {
+	case p.keyword("local"):
+		p.next()
+		name := p.next()
+		if name.kind != "name" || luaKeywords[name.value] {
+			return nil, fmt.Errorf("expected name after local, got %q", name.value)
+		}
+		if !p.at("op", "=") {
+			return &luaLocal{name: name.value}, nil
+		}
+		p.next()
+		value, err := p.expr(0)
+		return &luaLocal{name: name.value, value: value}, err
+
+	case p.keyword("return"):
+		p.next()
+		if p.at("eof", "") || p.keyword("end") || p.keyword("else") || p.keyword("elseif") {
+			return &luaReturn{}, nil
+		}
+		value, err := p.expr(0)
+		return &luaReturn{value: value}, err
+
+	case p.keyword("if"):
+		return p.ifStatement()
+	}
+
+	expr, err := p.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.at("op", "=") {
+		name, ok := expr.(*luaName)
+		if !ok {
+			return nil, errors.New("only plain variables can be assigned")
+		}
+		p.next()
+		value, err := p.expr(0)
+		return &luaAssign{name: name.name, value: value}, err
+	}
+	if _, ok := expr.(*luaCallExpr); !ok {
+		return nil, errors.New("syntax error: expression is not a statement")
+	}
+	return &luaExprStmt{expr: expr}, nil
+}
+
+func (p *luaParser) ifStatement() (luaStmt, error) {
+	stmt := &luaIf{}
+	for p.keyword("if") || p.keyword("elseif") {
+		p.next()
+		cond, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("name", "then"); err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		stmt.conds = append(stmt.conds, cond)
+		stmt.blocks = append(stmt.blocks, body)
+	}
+	if p.keyword("else") {
+		p.next()
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		stmt.orElse = body
+	}
+	return stmt, p.expect("name", "end")
+}
+
+// luaPrecedence - Lua 5.1 ikili operatör öncelikleri
+var luaPrecedence = map[string]int{
+	"or": 1, "and": 2,
+	"<": 3, ">": 3, "<=": 3, ">=": 3, "~=": 3, "==": 3,
+	"..": 4,
+	"+":  5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+const luaUnaryPrecedence = 7
+
+func (p *luaParser) expr(minPrec int) (luaExpr, error) {
+	var left luaExpr
+	if p.keyword("not") || p.at("op", "-") {
+		op := p.next().value
+		operand, err := p.expr(luaUnaryPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &luaUnary{op: op, operand: operand}
+	} else {
+		var err error
+		if left, err = p.suffixed(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		tok := p.peek()
+		prec, ok := luaPrecedence[tok.value]
+		if !ok || (tok.kind != "op" && tok.kind != "name") || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+		// .. sağdan birleşir
+		nextMin := prec
+		if tok.value == ".." {
+			nextMin = prec - 1
+		}
+		right, err := p.expr(nextMin)
+		if err != nil {
+			return nil, err
+		}
+		left = &luaBinary{op: tok.value, left: left, right: right}
+	}
+}
+
+func (p *luaParser) suffixed() (luaExpr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.at("op", "."):
+			p.next()
+			name := p.next()
+			if name.kind != "name" {
+				return nil, fmt.Errorf("expected field name, got %q", name.value)
+			}
+			expr = &luaIndex{target: expr, key: &luaConst{name.value}}
+		case p.at("op", "["):
+			p.next()
+			key, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("op", "]"); err != nil {
+				return nil, err
+			}
+			expr = &luaIndex{target: expr, key: key}
+		case p.at("op", "("):
+			p.next()
+			args, err := p.exprList(")")
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaCallExpr{fn: expr, args: args}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *luaParser) primary() (luaExpr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case "number":
+		n, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed number %q", tok.value)
+		}
+		return &luaConst{n}, nil
+	case "string":
+		return &luaConst{tok.value}, nil
+	case "name":
+		switch tok.value {
+		case "nil":
+			return &luaConst{nil}, nil
+		case "true":
+			return &luaConst{true}, nil
+		case "false":
+			return &luaConst{false}, nil
+		}
+		if luaKeywords[tok.value] {
+			return nil, fmt.Errorf("unexpected %q", tok.value)
+		}
+		return &luaName{tok.value}, nil
+	case "op":
+		switch tok.value {
+		case "(":
+			expr, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect("op", ")")
+		case "{":
+			items, err := p.exprList("}")
+			return &luaTableExpr{items: items}, err
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.value)
+}
+
+// exprList - Kapanış token'ına kadar virgülle ayrılmış ifadeler
+func (p *luaParser) exprList(closing string) ([]luaExpr, error) {
+	var items []luaExpr
+	for !p.at("op", closing) {
+		item, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.at("op", ",") {
+			break
+		}
+		p.next()
+	}
+	return items, p.expect("op", closing)
+}
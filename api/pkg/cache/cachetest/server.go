@@ -0,0 +1,798 @@
+// Package cachetest - Testler için bellek içi, RESP2 konuşan minimal Redis sunucusu
+// cache paketinin kullandığı komutları destekler; Lua script'leri küçük bir yorumlayıcıyla gerçekten çalıştırılır,
+// istenirse HandleScript ile Go karşılığı verilebilir
+package cachetest
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fiber-app/pkg/cache"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScriptFunc - EVAL/EVALSHA ile çalıştırılan bir script'in Go karşılığı
+// Dönen değer int64, string, []interface{}, nil veya error olabilir
+type ScriptFunc func(s *Server, keys, args []string) interface{}
+
+type entry struct {
+	value    string
+	zset     map[string]float64
+	expireAt time.Time
+}
+
+var errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// Server - Bellek içi Redis
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	data     map[string]*entry
+	offset   time.Duration
+	commands map[string]int
+	scripts  map[string]string
+	handlers map[string]ScriptFunc
+	failures map[string]string
+}
+
+// status - "+OK" gibi simple string yanıtı
+type status string
+
+// Start - Sunucuyu başlatır ve cache.RedisClient'ı ona bağlar; test bitince eski client geri yüklenir
+func Start(t testing.TB) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cachetest: listen: %v", err)
+	}
+
+	s := &Server{
+		listener: listener,
+		data:     make(map[string]*entry),
+		commands: make(map[string]int),
+		scripts:  make(map[string]string),
+		handlers: make(map[string]ScriptFunc),
+		failures: make(map[string]string),
+	}
+	go s.serve()
+
+	previous := cache.RedisClient
+	client := redis.NewClient(&redis.Options{
+		Addr:             listener.Addr().String(),
+		Protocol:         2,
+		DisableIndentity: true,
+	})
+	cache.RedisClient = client
+
+	t.Cleanup(func() {
+		cache.RedisClient = previous
+		client.Close()
+		listener.Close()
+	})
+
+	return s
+}
+
+// HandleScript - Gövdesinde marker geçen script'ler yorumlayıcı yerine fn ile çalıştırılır
+func (s *Server) HandleScript(marker string, fn ScriptFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[marker] = fn
+}
+
+// FailCommand - İlk argümanı (genelde key) prefix ile başlayan komut çağrıları hata döner
+func (s *Server) FailCommand(name, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[strings.ToUpper(name)] = prefix
+}
+
+// FastForward - Sunucu saatini ileri alır (TTL'leri test etmek için)
+func (s *Server) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+}
+
+// Now - Sunucunun (ileri alınmış olabilecek) saati
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now()
+}
+
+// CommandCount - Komutun (büyük harf, örn. "KEYS") kaç kez çalıştırıldığı
+func (s *Server) CommandCount(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands[strings.ToUpper(name)]
+}
+
+// Exists - Key var ve süresi dolmamış mı
+func (s *Server) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key) != nil
+}
+
+// Get - Key'in ham değeri
+func (s *Server) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.get(key); e != nil {
+		return e.value, true
+	}
+	return "", false
+}
+
+// Set - Key'i (TTL'siz) doğrudan yazar
+func (s *Server) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = &entry{value: value}
+}
+
+// TTL - Key'in kalan süresi (TTL yoksa 0)
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.get(key); e != nil && !e.expireAt.IsZero() {
+		return e.expireAt.Sub(s.now())
+	}
+	return 0
+}
+
+// Keys - Pattern'e uyan key'ler (sıralı)
+func (s *Server) Keys(pattern string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys(pattern)
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// get - Süresi dolmuş key'leri silerek entry döner (mu tutulmalı)
+func (s *Server) get(key string) *entry {
+	e, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !s.now().Before(e.expireAt) {
+		delete(s.data, key)
+		return nil
+	}
+	return e
+}
+
+func (s *Server) keys(pattern string) []string {
+	re := globToRegexp(pattern)
+	var result []string
+	for key := range s.data {
+		if s.get(key) != nil && re.MatchString(key) {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	var queued [][]string
+	inMulti := false
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		var reply interface{}
+
+		switch {
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			reply = status("OK")
+		case name == "DISCARD":
+			inMulti, queued = false, nil
+			reply = status("OK")
+		case name == "EXEC":
+			// Kuyruktaki komutlar tek kilit altında, araya başka komut girmeden çalışır
+			s.mu.Lock()
+			replies := make([]interface{}, len(queued))
+			for i, cmd := range queued {
+				replies[i] = s.exec(cmd)
+			}
+			s.mu.Unlock()
+			inMulti, queued = false, nil
+			reply = replies
+		case inMulti:
+			queued = append(queued, args)
+			reply = status("QUEUED")
+		default:
+			s.mu.Lock()
+			reply = s.exec(args)
+			s.mu.Unlock()
+		}
+
+		writeReply(writer, reply)
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+var errSyntax = errors.New("ERR syntax error")
+
+// exec - Tek komutu çalıştırır (mu tutulmalı)
+func (s *Server) exec(args []string) interface{} {
+	name := strings.ToUpper(args[0])
+	s.commands[name]++
+	args = args[1:]
+
+	if prefix, ok := s.failures[name]; ok && len(args) > 0 && strings.HasPrefix(args[0], prefix) {
+		return errors.New("ERR injected failure")
+	}
+
+	switch name {
+	case "PING":
+		return status("PONG")
+	case "HELLO":
+		return errors.New("ERR unknown command 'HELLO'")
+	case "CLIENT", "SELECT":
+		return status("OK")
+	case "INFO":
+		return "# Server\r\nredis_version:7.2.0\r\n"
+	case "TIME":
+		now := s.now()
+		return []interface{}{strconv.FormatInt(now.Unix(), 10), strconv.Itoa(now.Nanosecond() / 1000)}
+	case "GET":
+		if e := s.get(args[0]); e != nil {
+			if e.zset != nil {
+				return errWrongType
+			}
+			return e.value
+		}
+		return nil
+	case "GETDEL":
+		e := s.get(args[0])
+		if e == nil {
+			return nil
+		}
+		delete(s.data, args[0])
+		return e.value
+	case "SET":
+		return s.set(args)
+	case "DEL":
+		var n int64
+		for _, key := range args {
+			if s.get(key) != nil {
+				delete(s.data, key)
+				n++
+			}
+		}
+		return n
+	case "EXISTS":
+		var n int64
+		for _, key := range args {
+			if s.get(key) != nil {
+				n++
+			}
+		}
+		return n
+	case "INCR", "DECR":
+		return s.incrBy(args[0], map[string]int64{"INCR": 1, "DECR": -1}[name])
+	case "EXPIRE", "PEXPIRE":
+		e := s.get(args[0])
+		if e == nil {
+			return int64(0)
+		}
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errSyntax
+		}
+		unit := time.Second
+		if name == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		e.expireAt = s.now().Add(time.Duration(n) * unit)
+		return int64(1)
+	case "TTL", "PTTL":
+		e := s.get(args[0])
+		if e == nil {
+			return int64(-2)
+		}
+		if e.expireAt.IsZero() {
+			return int64(-1)
+		}
+		remaining := e.expireAt.Sub(s.now())
+		if name == "PTTL" {
+			return remaining.Milliseconds()
+		}
+		return int64((remaining + time.Second - 1) / time.Second)
+	case "DBSIZE":
+		return int64(len(s.keys("*")))
+	case "FLUSHDB", "FLUSHALL":
+		s.data = make(map[string]*entry)
+		return status("OK")
+	case "KEYS":
+		return stringsReply(s.keys(args[0]))
+	case "SCAN":
+		return s.scan(args)
+	case "ZADD", "ZCARD", "ZREM", "ZREMRANGEBYSCORE", "ZRANGE":
+		return s.sortedSet(name, args)
+	case "SCRIPT":
+		if len(args) == 2 && strings.EqualFold(args[0], "LOAD") {
+			return s.loadScript(args[1])
+		}
+		return status("OK")
+	case "EVAL":
+		return s.eval(s.loadScript(args[0]), args[1:])
+	case "EVALSHA":
+		return s.eval(args[0], args[1:])
+	}
+
+	return fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+func (s *Server) set(args []string) interface{} {
+	if len(args) < 2 {
+		return errSyntax
+	}
+	key, value := args[0], args[1]
+
+	var (
+		ttl     time.Duration
+		nx, xx  bool
+		keepTTL bool
+	)
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return errSyntax
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return errSyntax
+			}
+			ttl = time.Duration(n) * time.Second
+			if strings.EqualFold(args[i], "PX") {
+				ttl = time.Duration(n) * time.Millisecond
+			}
+			i++
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "KEEPTTL":
+			keepTTL = true
+		default:
+			return errSyntax
+		}
+	}
+
+	existing := s.get(key)
+	if (nx && existing != nil) || (xx && existing == nil) {
+		return nil
+	}
+
+	e := &entry{value: value}
+	switch {
+	case ttl > 0:
+		e.expireAt = s.now().Add(ttl)
+	case keepTTL && existing != nil:
+		e.expireAt = existing.expireAt
+	}
+	s.data[key] = e
+
+	return status("OK")
+}
+
+func (s *Server) incrBy(key string, delta int64) interface{} {
+	e := s.get(key)
+	if e == nil {
+		e = &entry{value: "0"}
+		s.data[key] = e
+	}
+
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return errors.New("ERR value is not an integer or out of range")
+	}
+	n += delta
+	e.value = strconv.FormatInt(n, 10)
+	return n
+}
+
+// sortedSet - Z* komutları; üye/skor çiftleri entry.zset'te tutulur, boşalan set silinir
+func (s *Server) sortedSet(name string, args []string) interface{} {
+	if len(args) == 0 {
+		return errSyntax
+	}
+	key := args[0]
+
+	e := s.get(key)
+	if e != nil && e.zset == nil {
+		return errWrongType
+	}
+
+	switch name {
+	case "ZADD":
+		if len(args) < 3 || len(args)%2 == 0 {
+			return errSyntax
+		}
+		if e == nil {
+			e = &entry{zset: make(map[string]float64)}
+			s.data[key] = e
+		}
+		var added int64
+		for i := 1; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return errors.New("ERR value is not a valid float")
+			}
+			if _, ok := e.zset[args[i+1]]; !ok {
+				added++
+			}
+			e.zset[args[i+1]] = score
+		}
+		return added
+	case "ZCARD":
+		if e == nil {
+			return int64(0)
+		}
+		return int64(len(e.zset))
+	}
+
+	if e == nil {
+		if name == "ZRANGE" {
+			return []interface{}{}
+		}
+		return int64(0)
+	}
+
+	var removed int64
+	switch name {
+	case "ZREM":
+		for _, member := range args[1:] {
+			if _, ok := e.zset[member]; ok {
+				delete(e.zset, member)
+				removed++
+			}
+		}
+	case "ZREMRANGEBYSCORE":
+		if len(args) != 3 {
+			return errSyntax
+		}
+		inRange, err := scoreRange(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		for member, score := range e.zset {
+			if inRange(score) {
+				delete(e.zset, member)
+				removed++
+			}
+		}
+	case "ZRANGE":
+		return zrange(e.zset, args[1:])
+	}
+
+	if len(e.zset) == 0 {
+		delete(s.data, key)
+	}
+	return removed
+}
+
+// zrange - Skor (eşitlikte üye) sırasıyla index aralığı; negatif index sondan sayar
+func zrange(zset map[string]float64, args []string) interface{} {
+	if len(args) < 2 {
+		return errSyntax
+	}
+	start, err1 := strconv.Atoi(args[0])
+	stop, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		return errors.New("ERR value is not an integer or out of range")
+	}
+	withScores := len(args) == 3 && strings.EqualFold(args[2], "WITHSCORES")
+
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+
+	n := len(members)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+
+	reply := []interface{}{}
+	for i := start; i <= stop; i++ {
+		reply = append(reply, members[i])
+		if withScores {
+			reply = append(reply, strconv.FormatFloat(zset[members[i]], 'f', -1, 64))
+		}
+	}
+	return reply
+}
+
+// scoreRange - ZREMRANGEBYSCORE sınırları; "-inf"/"+inf" ve "(" ile dışlayan sınır desteklenir
+func scoreRange(minArg, maxArg string) (func(float64) bool, error) {
+	parse := func(arg string) (float64, bool, error) {
+		exclusive := strings.HasPrefix(arg, "(")
+		score, err := strconv.ParseFloat(strings.TrimPrefix(arg, "("), 64)
+		if err != nil {
+			return 0, false, errors.New("ERR min or max is not a float")
+		}
+		return score, exclusive, nil
+	}
+
+	lo, loExclusive, err := parse(minArg)
+	if err != nil {
+		return nil, err
+	}
+	hi, hiExclusive, err := parse(maxArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(score float64) bool {
+		aboveMin := score > lo || (!loExclusive && score == lo)
+		belowMax := score < hi || (!hiExclusive && score == hi)
+		return aboveMin && belowMax
+	}, nil
+}
+
+// scan - Cursor, sıralı key listesindeki offset'tir
+func (s *Server) scan(args []string) interface{} {
+	cursor, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errors.New("ERR invalid cursor")
+	}
+
+	pattern, count := "*", 10
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			if count, err = strconv.Atoi(args[i+1]); err != nil {
+				return errSyntax
+			}
+		}
+	}
+
+	// Gerçek Redis gibi COUNT kadar key incelenir, pattern'e uyanlar döner
+	all := s.keys("*")
+	end := cursor + count
+	if end >= len(all) {
+		end = len(all)
+	}
+
+	re := globToRegexp(pattern)
+	var matched []string
+	for _, key := range all[min(cursor, len(all)):end] {
+		if re.MatchString(key) {
+			matched = append(matched, key)
+		}
+	}
+
+	next := end
+	if next >= len(all) {
+		next = 0
+	}
+	return []interface{}{strconv.Itoa(next), stringsReply(matched)}
+}
+
+func (s *Server) loadScript(body string) string {
+	sum := sha1.Sum([]byte(body))
+	sha := hex.EncodeToString(sum[:])
+	s.scripts[sha] = body
+	return sha
+}
+
+func (s *Server) eval(sha string, args []string) interface{} {
+	body, ok := s.scripts[strings.ToLower(sha)]
+	if !ok {
+		return errors.New("NOSCRIPT No matching script. Please use EVAL.")
+	}
+
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil || numKeys > len(args)-1 {
+		return errSyntax
+	}
+	keys, argv := args[1:1+numKeys], args[1+numKeys:]
+
+	for marker, fn := range s.handlers {
+		if strings.Contains(body, marker) {
+			return fn(s, keys, argv)
+		}
+	}
+	return s.runLua(body, keys, argv)
+}
+
+// Script handler'ları için, kilit zaten tutulurken kullanılan yardımcılar
+
+// GetLocked - Script handler içinden key okur
+func (s *Server) GetLocked(key string) (string, bool) {
+	if e := s.get(key); e != nil {
+		return e.value, true
+	}
+	return "", false
+}
+
+// SetLocked - Script handler içinden key yazar (ttl 0 ise süresiz)
+func (s *Server) SetLocked(key, value string, ttl time.Duration) {
+	e := &entry{value: value}
+	if ttl > 0 {
+		e.expireAt = s.now().Add(ttl)
+	}
+	s.data[key] = e
+}
+
+// NowLocked - Script handler içinden sunucu saati
+func (s *Server) NowLocked() time.Time {
+	return s.now()
+}
+
+func stringsReply(values []string) []interface{} {
+	reply := make([]interface{}, len(values))
+	for i, v := range values {
+		reply[i] = v
+	}
+	return reply
+}
+
+// globToRegexp - Redis glob pattern'ini (*, ?, [...], \x) regexp'e çevirir
+func globToRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(string(ch)))
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\-`, "-") + "]")
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// readCommand - RESP array'i (veya inline komutu) okur
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("unexpected header %q", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case status:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", v.Error())
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case int:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	default:
+		fmt.Fprintf(w, "-ERR cachetest: unsupported reply %T\r\n", v)
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotConnected - Redis bağlantısı kurulmamış
+var ErrNotConnected = errors.New("redis not connected")
+
+// slidingWindowScript - Sorted set ile sliding window sayacı; kontrol ve kayıt tek atomik adımda
+// Zaman Redis'ten alınır, böylece farklı instance'ların saat farkı sonucu etkilemez
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local retry = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry}
+`)
+
+// RateLimitResult - Sliding window kontrolünün sonucu
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// SlidingWindowAllow - key için window içinde limit'ten az istek varsa isteği sayar ve izin verir
+func SlidingWindowAllow(key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	if RedisClient == nil {
+		return nil, ErrNotConnected
+	}
+
+	values, err := slidingWindowScript.Run(ctx, RedisClient, []string{key},
+		window.Milliseconds(), limit, uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/cache/cachetest"
+	"testing"
+	"time"
+)
+
+// SlidingWindowAllow'un Lua script'i cachetest'te gerçekten çalıştırılır; Go karşılığı yoktur
+
+func TestSlidingWindowAllow(t *testing.T) {
+	redis := cachetest.Start(t)
+	const key = "ratelimit:client"
+
+	for i := 0; i < 3; i++ {
+		result, err := cache.SlidingWindowAllow(key, 3, time.Minute)
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		if !result.Allowed || result.Remaining != 2-i || result.RetryAfter != 0 {
+			t.Errorf("request %d = %+v, want allowed with %d remaining", i+1, result, 2-i)
+		}
+		redis.FastForward(10 * time.Second)
+	}
+
+	// Script key'e pencere kadar TTL verir; boşta kalan sayaç Redis'te birikmez
+	if ttl := redis.TTL(key); ttl <= 30*time.Second || ttl > time.Minute {
+		t.Errorf("key TTL = %v, want the window measured from the last request", ttl)
+	}
+
+	result, err := cache.SlidingWindowAllow(key, 3, time.Minute)
+	if err != nil {
+		t.Fatalf("over the limit: %v", err)
+	}
+	// En eski istek 30s önceydi; pencereden çıkmasına 30s var
+	if result.Allowed || result.Remaining != 0 {
+		t.Errorf("over the limit = %+v, want rejected", result)
+	}
+	if result.RetryAfter <= 29*time.Second || result.RetryAfter > 30*time.Second {
+		t.Errorf("RetryAfter = %v, want ~30s", result.RetryAfter)
+	}
+
+	// Reddedilen istek sayılmaz: en eski istek düşünce tek slot açılır
+	redis.FastForward(31 * time.Second)
+	if result, _ := cache.SlidingWindowAllow(key, 3, time.Minute); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("after the oldest request expired = %+v, want allowed with 0 remaining", result)
+	}
+	if result, _ := cache.SlidingWindowAllow(key, 3, time.Minute); result.Allowed {
+		t.Error("window should be full again")
+	}
+}
+
+func TestSlidingWindowAllowScriptError(t *testing.T) {
+	redis := cachetest.Start(t)
+
+	// Key başka tipte bir değer tutuyorsa script'teki redis.call hatası çağırana döner
+	redis.Set("ratelimit:client", "not a sorted set")
+	if _, err := cache.SlidingWindowAllow("ratelimit:client", 3, time.Minute); err == nil {
+		t.Error("expected the script error to be returned")
+	}
+}
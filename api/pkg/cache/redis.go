@@ -16,6 +16,9 @@ var (
 	ctx         = context.Background()
 )
 
+// DefaultScanCount - SCAN'in her iterasyonda incelemesi istenen key sayısı
+const DefaultScanCount = 1000
+
 // Connect - Redis bağlantısı kur
 func Connect(cfg *config.Config, zapLogger *zap.Logger) error {
 	addr := fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port)
@@ -53,6 +56,39 @@ func Set(key string, value interface{}, ttl time.Duration) error {
 	return RedisClient.Set(ctx, key, jsonValue, ttl).Err()
 }
 
+// SetNX - Key yoksa kaydet, kaydedildiyse true döner (lock için)
+func SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return RedisClient.SetNX(ctx, key, jsonValue, ttl).Result()
+}
+
+// Incr - Sayacı artırır ve TTL'ini yeniler, yeni değeri döner
+func Incr(key string, ttl time.Duration) (int64, error) {
+	pipe := RedisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Decr - Sayacı azaltır, sıfıra inen sayacı siler
+func Decr(key string) error {
+	value, err := RedisClient.Decr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if value <= 0 {
+		return RedisClient.Del(ctx, key).Err()
+	}
+	return nil
+}
+
 // Get - Key ile value al
 func Get(key string, dest interface{}) error {
 	val, err := RedisClient.Get(ctx, key).Result()
@@ -63,23 +99,34 @@ func Get(key string, dest interface{}) error {
 	return json.Unmarshal([]byte(val), dest)
 }
 
-// Delete - Key'i sil
-func Delete(key string) error {
-	return RedisClient.Del(ctx, key).Err()
-}
-
-// DeletePattern - Pattern'e uyan key'leri sil
-func DeletePattern(pattern string) error {
-	keys, err := RedisClient.Keys(ctx, pattern).Result()
+// GetDel - Key'i atomik olarak okur ve siler (tek kullanımlık değerler için)
+func GetDel(key string, dest interface{}) error {
+	val, err := RedisClient.GetDel(ctx, key).Result()
 	if err != nil {
 		return err
 	}
 
-	if len(keys) > 0 {
-		return RedisClient.Del(ctx, keys...).Err()
+	return json.Unmarshal([]byte(val), dest)
+}
+
+// Delete - Key'leri sil
+func Delete(keys ...string) error {
+	return RedisClient.Del(ctx, keys...).Err()
+}
+
+// DeleteCount - Key'leri sil, gerçekten silinen (var olan) key sayısını döner
+func DeleteCount(keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
 	}
+	return RedisClient.Del(ctx, keys...).Result()
+}
 
-	return nil
+// DeletePattern - Pattern'e uyan key'leri SCAN ile batch'ler halinde sil
+func DeletePattern(pattern string) error {
+	return ScanBatches(pattern, DefaultScanCount, func(keys []string) error {
+		return RedisClient.Del(ctx, keys...).Err()
+	})
 }
 
 // Exists - Key var mı kontrol et
@@ -104,10 +151,53 @@ func FlushDB() error {
 }
 
 // Keys - Pattern'e uyan key'leri listele
+// KEYS tüm keyspace'i tek seferde tarayıp Redis'i bloklar, büyük veri setlerinde Scan kullanılmalı
 func Keys(pattern string) ([]string, error) {
 	return RedisClient.Keys(ctx, pattern).Result()
 }
 
+// ScanBatches - Pattern'e uyan key'leri SCAN cursor'ı ile gezer, her boş olmayan batch için fn çağrılır
+// Her SCAN çağrısı küçük bir iş yaptığı için diğer komutlar arada çalışabilir (KEYS gibi bloklamaz)
+// SCAN aynı key'i birden fazla döndürebilir, fn idempotent olmalı
+func ScanBatches(pattern string, count int64, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := RedisClient.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// Scan - Pattern'e uyan key'leri SCAN ile (tekrarsız) listele
+func Scan(pattern string, count int64) ([]string, error) {
+	seen := make(map[string]struct{})
+	var result []string
+
+	err := ScanBatches(pattern, count, func(keys []string) error {
+		for _, key := range keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				result = append(result, key)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
 // TTL - Key'in kalan yaşam süresi
 func TTL(key string) (time.Duration, error) {
 	return RedisClient.TTL(ctx, key).Result()
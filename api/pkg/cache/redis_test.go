@@ -0,0 +1,111 @@
+package cache_test
+
+import (
+	"errors"
+	"fiber-app/pkg/cache"
+	"fiber-app/pkg/cache/cachetest"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// seedKeys - n adet session ve n adet user key'i yazar
+func seedKeys(redis *cachetest.Server, n int) {
+	for i := 0; i < n; i++ {
+		redis.Set(fmt.Sprintf("session:user-%d", i), "{}")
+		redis.Set(fmt.Sprintf("user:%d", i), "{}")
+	}
+}
+
+func TestScanMatchesKeysInBatches(t *testing.T) {
+	redis := cachetest.Start(t)
+	seedKeys(redis, 1000)
+
+	scanned, err := cache.Scan("session:*", 100)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	sort.Strings(scanned)
+
+	if want := redis.Keys("session:*"); fmt.Sprint(scanned) != fmt.Sprint(want) {
+		t.Errorf("Scan returned %d keys, want the %d keys KEYS would return", len(scanned), len(want))
+	}
+
+	// Keyspace (2000 key) tek komutta değil COUNT'lık adımlarla gezilir
+	if got := redis.CommandCount("SCAN"); got != 20 {
+		t.Errorf("SCAN calls = %d, want 20", got)
+	}
+	if got := redis.CommandCount("KEYS"); got != 0 {
+		t.Errorf("KEYS calls = %d, want 0", got)
+	}
+}
+
+func TestScanBatchesStopsOnError(t *testing.T) {
+	redis := cachetest.Start(t)
+	seedKeys(redis, 500)
+
+	stop := errors.New("stop")
+	batches := 0
+	err := cache.ScanBatches("*", 100, func([]string) error {
+		batches++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("err = %v, want the callback error", err)
+	}
+	if batches != 1 || redis.CommandCount("SCAN") != 1 {
+		t.Errorf("batches = %d, SCAN calls = %d, want iteration to stop after the first batch", batches, redis.CommandCount("SCAN"))
+	}
+}
+
+func TestDeletePatternUsesScan(t *testing.T) {
+	redis := cachetest.Start(t)
+	seedKeys(redis, 300)
+
+	if err := cache.DeletePattern("session:*"); err != nil {
+		t.Fatalf("DeletePattern: %v", err)
+	}
+	if left := redis.Keys("session:*"); len(left) != 0 {
+		t.Errorf("%d session keys left", len(left))
+	}
+	if len(redis.Keys("user:*")) != 300 {
+		t.Error("keys outside the pattern were deleted")
+	}
+	if got := redis.CommandCount("KEYS"); got != 0 {
+		t.Errorf("KEYS calls = %d, want 0", got)
+	}
+}
+
+// BenchmarkScan - Her SCAN çağrısı en fazla COUNT key inceler; sunucu tek komutta tüm keyspace'e kilitlenmez
+// KEYS ile karşılaştırmak için: go test ./pkg/cache -bench . -benchmem
+func BenchmarkScan(b *testing.B) {
+	redis := cachetest.Start(b)
+	seedKeys(redis, 5000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.Scan("session:*", cache.DefaultScanCount); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(redis.CommandCount("SCAN"))/float64(b.N), "commands/op")
+	b.ReportMetric(float64(cache.DefaultScanCount), "keys/command")
+}
+
+func BenchmarkKeys(b *testing.B) {
+	redis := cachetest.Start(b)
+	seedKeys(redis, 5000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.Keys("session:*"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(redis.CommandCount("KEYS"))/float64(b.N), "commands/op")
+	b.ReportMetric(float64(len(redis.Keys("*"))), "keys/command")
+}
@@ -3,15 +3,23 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port     string
 	LogLevel string
 	AppEnv   string
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Zitadel  ZitadelConfig
+	// Swagger UI, swagger.json ve /docs (production'da default kapalı)
+	DocsEnabled bool
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	Zitadel     ZitadelConfig
+	JWT         JWTConfig
+	Limits      FieldLimits
+	Concurrency ConcurrencyConfig
+	RateLimit   RateLimitConfig
 }
 
 type DatabaseConfig struct {
@@ -21,6 +29,56 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	SlowQueryThreshold time.Duration
+}
+
+// FieldLimits - Kullanıcı/rol alanları için maksimum uzunluklar (DB kolon boyutlarını aşmamalı)
+type FieldLimits struct {
+	UserName        int
+	UserEmail       int
+	RoleName        int
+	RoleDescription int
+}
+
+// ColumnLimits - models'deki size tag'leriyle aynı kolon boyutları; FieldLimits bunları aşamaz
+var ColumnLimits = FieldLimits{
+	UserName:        100,
+	UserEmail:       255,
+	RoleName:        50,
+	RoleDescription: 500,
+}
+
+// Clamp - Kolon boyutunu aşan ya da pozitif olmayan (limitsiz) değerleri kolon boyutuna indirir
+func (fl FieldLimits) Clamp(columns FieldLimits) FieldLimits {
+	clamp := func(limit, column int) int {
+		if limit <= 0 || limit > column {
+			return column
+		}
+		return limit
+	}
+
+	return FieldLimits{
+		UserName:        clamp(fl.UserName, columns.UserName),
+		UserEmail:       clamp(fl.UserEmail, columns.UserEmail),
+		RoleName:        clamp(fl.RoleName, columns.RoleName),
+		RoleDescription: clamp(fl.RoleDescription, columns.RoleDescription),
+	}
+}
+
+// ConcurrencyConfig - Route grubu başına eşzamanlı istek limitleri (DB connection pool'unu korumak için)
+type ConcurrencyConfig struct {
+	// /api/v1/users altında aynı anda işlenebilecek istek sayısı (0 = limitsiz)
+	Users int
+	// Limit doluyken bir isteğin slot için bekleyebileceği süre (0 = beklemeden 503)
+	QueueTimeout time.Duration
+}
+
+// RateLimitConfig - Redis tabanlı istek limitleri (credential stuffing / token deneme saldırılarına karşı)
+type RateLimitConfig struct {
+	// /auth altında IP başına window içinde izin verilen istek sayısı (0 = limitsiz)
+	Auth   int
+	Window time.Duration
 }
 
 type RedisConfig struct {
@@ -36,13 +94,83 @@ type ZitadelConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+
+	// Login isteğinde redirect_uri ile seçilebilecek ek callback URL'leri (birebir eşleşme; loopback'te port serbest)
+	AllowedRedirectURLs []string
+	// Native uygulamalar için izinli custom redirect scheme'leri (örn. myapp)
+	NativeRedirectSchemes []string
+
+	LogTokenFailures bool
+
+	// ID token'daki azp claim'i ClientID ile eşleşmeli
+	CheckAzp bool
+
+	// PKCE code verifier'ın login başlangıcından callback'e kadar saklandığı süre
+	PKCETTL time.Duration
+
+	// "local" (sadece uygulamanın JWT'leri) veya "introspection" (opaque IdP token'ları RFC 7662 ile)
+	TokenValidationMode string
+	// Aktif introspection sonuçlarının cache süresi (0 = cache yok)
+	IntrospectionCacheTTL time.Duration
+	// Zitadel'in JWT access token vermesi zorunlu (opaque token'lar login'de reddedilir)
+	RequireJWTAccessTokens bool
+
+	// Step-up authentication gereksinimleri (boşsa kontrol edilmez)
+	RequiredACR string
+	RequiredAMR []string
+
+	// Bir IP'nin aynı anda bekleyebileceği maksimum login state sayısı (0 = limitsiz)
+	MaxPendingStatesPerIP int
+
+	// mTLS sonlandıran proxy'nin istemci sertifikasını ilettiği header (örn. X-Client-Cert)
+	ClientCertHeader string
+
+	// Logout'ta Zitadel oturumunu da kapatmak için end_session URL'i döndürülür
+	RPLogout bool
+	// Zitadel logout sonrası kullanıcının yönlendirileceği adres (boşsa Zitadel varsayılanı)
+	PostLogoutRedirectURL string
+
+	// Session'da saklanan IdP token'larını şifreleyen AES-256 key'i (base64, 32 byte; boşsa client secret'tan türetilir)
+	SessionEncryptionKey string
+}
+
+// JWTConfig - Uygulamanın kendi imzaladığı token'ların ayarları
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	TokenTTL time.Duration
+	// Token'daki tüm audience'lar Audience ile eşleşmeli (default: biri eşleşmesi yeterli)
+	RejectExtraAudiences bool
+
+	// exp/nbf/iat kontrollerinde sunucular arası saat farkı toleransı
+	ClockSkew time.Duration
+	// ClockSkew için üst sınır; daha büyük değerler bu sınıra indirilir
+	MaxClockSkew time.Duration
+
+	// PEM formatında RSA private key dosyası (boşsa her başlangıçta geçici key üretilir)
+	SigningKeyFile string
+	// Rotasyon sonrası eski token'ların doğrulanabilmesi için önceki key dosyaları
+	PreviousKeyFiles []string
+	// SigningKeyFile boşsa başlatma reddedilir (production'da default açık)
+	RequireSigningKeyFile bool
+
+	// Server-rendered sayfalar için access token'ın okunacağı cookie (boşsa sadece Authorization header)
+	AccessTokenCookie string
+
+	// Süresi dolmuş ama session'ı hâlâ geçerli olan token'lar kabul edilir, client'a yenileme sinyali verilir
+	ExpiredTokenSessionFallback bool
+	// Fallback'te token'ın exp'inin en fazla ne kadar geçmişte olabileceği
+	ExpiredTokenGrace time.Duration
 }
 
 func Load() *Config {
+	appEnv := getEnv("APP_ENV", "development")
+
 	return &Config{
-		Port:     getEnv("PORT", "3000"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
-		AppEnv:   getEnv("APP_ENV", "development"),
+		Port:        getEnv("PORT", "3000"),
+		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		AppEnv:      appEnv,
+		DocsEnabled: getEnvAsBool("DOCS_ENABLED", appEnv != "production"),
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
@@ -50,6 +178,8 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "fiber_app"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			SlowQueryThreshold: time.Duration(getEnvAsInt("DB_SLOW_QUERY_MS", 200)) * time.Millisecond,
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -57,12 +187,73 @@ func Load() *Config {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
+		// Kolon boyutunu aşan limitler insert'te DB hatasına dönmesin diye kolon boyutuna indirilir
+		Limits: FieldLimits{
+			UserName:        getEnvAsInt("MAX_USER_NAME_LENGTH", ColumnLimits.UserName),
+			UserEmail:       getEnvAsInt("MAX_USER_EMAIL_LENGTH", ColumnLimits.UserEmail),
+			RoleName:        getEnvAsInt("MAX_ROLE_NAME_LENGTH", ColumnLimits.RoleName),
+			RoleDescription: getEnvAsInt("MAX_ROLE_DESCRIPTION_LENGTH", ColumnLimits.RoleDescription),
+		}.Clamp(ColumnLimits),
+		Concurrency: ConcurrencyConfig{
+			Users:        getEnvAsInt("USERS_MAX_CONCURRENT", 20),
+			QueueTimeout: time.Duration(getEnvAsInt("CONCURRENCY_QUEUE_TIMEOUT_MS", 500)) * time.Millisecond,
+		},
+		RateLimit: RateLimitConfig{
+			Auth:   getEnvAsInt("AUTH_RATE_LIMIT", 30),
+			Window: time.Duration(getEnvAsInt("RATE_LIMIT_WINDOW_SECONDS", 60)) * time.Second,
+		},
 		Zitadel: ZitadelConfig{
 			Domain:       getEnv("ZITADEL_DOMAIN", "http://localhost:8080"),
 			ClientID:     getEnv("ZITADEL_CLIENT_ID", ""),
 			ClientSecret: getEnv("ZITADEL_CLIENT_SECRET", ""),
 			RedirectURL:  getEnv("ZITADEL_REDIRECT_URL", "http://localhost:3003/auth/callback"),
 			Scopes:       []string{"openid", "profile", "email", "urn:zitadel:iam:org:project:roles"},
+
+			AllowedRedirectURLs:   getEnvAsSlice("ZITADEL_ALLOWED_REDIRECT_URLS", nil),
+			NativeRedirectSchemes: getEnvAsSlice("ZITADEL_NATIVE_REDIRECT_SCHEMES", nil),
+
+			LogTokenFailures: getEnvAsBool("LOG_TOKEN_FAILURES", true),
+
+			CheckAzp: getEnvAsBool("ZITADEL_CHECK_AZP", false),
+
+			PKCETTL: time.Duration(getEnvAsInt("PKCE_TTL_MINUTES", 10)) * time.Minute,
+
+			TokenValidationMode:   getEnv("TOKEN_VALIDATION_MODE", "local"),
+			IntrospectionCacheTTL: time.Duration(getEnvAsInt("INTROSPECTION_CACHE_SECONDS", 60)) * time.Second,
+
+			RequireJWTAccessTokens: getEnvAsBool("ZITADEL_REQUIRE_JWT_ACCESS_TOKENS", false),
+
+			RequiredACR: getEnv("ZITADEL_REQUIRED_ACR", ""),
+			RequiredAMR: getEnvAsSlice("ZITADEL_REQUIRED_AMR", nil),
+
+			MaxPendingStatesPerIP: getEnvAsInt("AUTH_MAX_PENDING_STATES_PER_IP", 20),
+
+			ClientCertHeader: getEnv("CLIENT_CERT_HEADER", ""),
+
+			RPLogout:              getEnvAsBool("ZITADEL_RP_LOGOUT", true),
+			PostLogoutRedirectURL: getEnv("ZITADEL_POST_LOGOUT_REDIRECT_URL", ""),
+
+			SessionEncryptionKey: getEnv("SESSION_ENCRYPTION_KEY", ""),
+		},
+		JWT: JWTConfig{
+			Issuer:   getEnv("JWT_ISSUER", "fiber-app"),
+			Audience: getEnv("JWT_AUDIENCE", "fiber-app"),
+			TokenTTL: time.Duration(getEnvAsInt("JWT_TOKEN_TTL_MINUTES", 1440)) * time.Minute,
+
+			RejectExtraAudiences: getEnvAsBool("JWT_REJECT_EXTRA_AUDIENCES", false),
+
+			ClockSkew:    time.Duration(getEnvAsInt("JWT_CLOCK_SKEW_SECONDS", 0)) * time.Second,
+			MaxClockSkew: time.Duration(getEnvAsInt("JWT_MAX_CLOCK_SKEW_SECONDS", 300)) * time.Second,
+
+			SigningKeyFile:   getEnv("JWT_SIGNING_KEY_FILE", ""),
+			PreviousKeyFiles: getEnvAsSlice("JWT_PREVIOUS_KEY_FILES", nil),
+
+			RequireSigningKeyFile: getEnvAsBool("JWT_REQUIRE_SIGNING_KEY_FILE", appEnv == "production"),
+
+			AccessTokenCookie: getEnv("JWT_ACCESS_TOKEN_COOKIE", ""),
+
+			ExpiredTokenSessionFallback: getEnvAsBool("JWT_EXPIRED_TOKEN_SESSION_FALLBACK", false),
+			ExpiredTokenGrace:           time.Duration(getEnvAsInt("JWT_EXPIRED_TOKEN_GRACE_MINUTES", 15)) * time.Minute,
 		},
 	}
 }
@@ -82,3 +273,25 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var result []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return defaultValue
+}
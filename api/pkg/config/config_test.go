@@ -0,0 +1,81 @@
+package config
+
+import "testing"
+
+func TestLoadClampsFieldLimitsToColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  FieldLimits
+	}{
+		{"unset", "", ColumnLimits},
+		{"below column", "40", FieldLimits{UserName: 40, UserEmail: 40, RoleName: 40, RoleDescription: 40}},
+		{"over column", "10000", ColumnLimits},
+		{"zero", "0", ColumnLimits},
+		{"negative", "-1", ColumnLimits},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"MAX_USER_NAME_LENGTH", "MAX_USER_EMAIL_LENGTH", "MAX_ROLE_NAME_LENGTH", "MAX_ROLE_DESCRIPTION_LENGTH"} {
+				t.Setenv(key, tt.value)
+			}
+			if got := Load().Limits; got != tt.want {
+				t.Errorf("Limits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldLimitsClampPerField(t *testing.T) {
+	limits := FieldLimits{UserName: 100, UserEmail: 300, RoleName: 20, RoleDescription: 0}
+	want := FieldLimits{UserName: 100, UserEmail: 255, RoleName: 20, RoleDescription: 500}
+
+	if got := limits.Clamp(ColumnLimits); got != want {
+		t.Errorf("Clamp = %+v, want %+v", got, want)
+	}
+}
+
+func TestDocsEnabledDefault(t *testing.T) {
+	tests := []struct {
+		appEnv, docsEnabled string
+		want                bool
+	}{
+		{"development", "", true},
+		{"production", "", false},
+		{"production", "true", true},
+		{"development", "false", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.appEnv+"/"+tt.docsEnabled, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.appEnv)
+			t.Setenv("DOCS_ENABLED", tt.docsEnabled)
+			if got := Load().DocsEnabled; got != tt.want {
+				t.Errorf("DocsEnabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireSigningKeyFileDefault(t *testing.T) {
+	tests := []struct {
+		appEnv, require string
+		want            bool
+	}{
+		{"development", "", false},
+		{"production", "", true},
+		{"production", "false", false},
+		{"development", "true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.appEnv+"/"+tt.require, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.appEnv)
+			t.Setenv("JWT_REQUIRE_SIGNING_KEY_FILE", tt.require)
+			if got := Load().JWT.RequireSigningKeyFile; got != tt.want {
+				t.Errorf("RequireSigningKeyFile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -30,8 +30,14 @@ func Connect(cfg *config.Config, zapLogger *zap.Logger) error {
 	)
 
 	var err error
+	// debug seviyesinde tüm query'ler, aksi halde sadece hata ve yavaş query'ler loglanır
+	logLevel := logger.Warn
+	if cfg.LogLevel == "debug" {
+		logLevel = logger.Info
+	}
+
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent), // GORM loglarını kapat
+		Logger: newGormLogger(zapLogger, logLevel, cfg.Database.SlowQueryThreshold),
 	})
 
 	if err != nil {
@@ -44,10 +50,57 @@ func Connect(cfg *config.Config, zapLogger *zap.Logger) error {
 }
 
 func Migrate() error {
-	return DB.AutoMigrate(
+	if err := DB.AutoMigrate(
 		&models.Role{},
 		&models.User{},
-	)
+		&models.RoleHistory{},
+	); err != nil {
+		return err
+	}
+
+	return dropLegacyUserEmailIndexes()
+}
+
+// dropLegacyUserEmailIndexes - Soft delete öncesi tüm satırları kapsayan email unique index'lerini kaldırır
+// Aksi halde silinmiş bir kullanıcının email'i ile yeni kullanıcı oluşturulamaz (yerine idx_users_email_active)
+func dropLegacyUserEmailIndexes() error {
+	return DB.Exec(`
+		ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+		DROP INDEX IF EXISTS idx_users_email;
+	`).Error
+}
+
+// TableStatus - Migrate edilen bir tablonun durumu
+type TableStatus struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+	Rows   int64  `json:"rows"`
+}
+
+// Status - Migrate edilen tabloların varlık ve kayıt sayısı bilgisi
+func Status() ([]TableStatus, error) {
+	tables := []struct {
+		name  string
+		model interface{}
+	}{
+		{"roles", &models.Role{}},
+		{"users", &models.User{}},
+		{"role_histories", &models.RoleHistory{}},
+	}
+
+	status := make([]TableStatus, 0, len(tables))
+	for _, table := range tables {
+		ts := TableStatus{Name: table.name}
+		if DB.Migrator().HasTable(table.model) {
+			ts.Exists = true
+			if err := DB.Model(table.model).Count(&ts.Rows).Error; err != nil {
+				return nil, err
+			}
+		}
+		status = append(status, ts)
+	}
+
+	return status, nil
 }
 
 func SeedDefaultRoles() error {
@@ -0,0 +1,335 @@
+// Package dbtest - Testler için SQL'i kaydeden, sorgulara önceden tanımlanmış satırlar dönen sahte veritabanı
+// GORM postgres dialect'i ile kullanılır; üretilen SQL gerçek bir Postgres'e gitmeden doğrulanabilir
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fiber-app/pkg/database"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement - Çalıştırılan bir SQL ifadesi ve argümanları
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+	// Transaction içinde çalıştırıldı mı
+	InTx bool
+}
+
+// Result - Bir sorguya dönülecek kolonlar ve satırlar
+type Result struct {
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// QueryFunc - Sorguya dönülecek sonucu üretir
+type QueryFunc func(query string, args []driver.Value) Result
+
+// ExecFunc - Exec'i işler ve etkilenen satır sayısını döner
+type ExecFunc func(query string, args []driver.Value) int64
+
+type handler struct {
+	marker string
+	fn     QueryFunc
+}
+
+type execHandler struct {
+	marker string
+	fn     ExecFunc
+}
+
+// DB - Sahte veritabanı
+type DB struct {
+	mu           sync.Mutex
+	statements   []Statement
+	handlers     []handler
+	execHandlers []execHandler
+	rowsAffected map[string]int64
+	failures     map[string]error
+	commits      int
+	rollbacks    int
+	inTx         bool
+}
+
+// Open - Sahte veritabanını açar ve database.DB'ye bağlar; test bitince eski bağlantı geri yüklenir
+func Open(t testing.TB) *DB {
+	t.Helper()
+
+	fake := &DB{rowsAffected: make(map[string]int64), failures: make(map[string]error)}
+	sqlDB := sql.OpenDB(connector{fake})
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("dbtest: open: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = gormDB
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+
+	return fake
+}
+
+// HandleQuery - SQL'inde marker geçen sorgulara fn'in döndüğü satırlar verilir (son eklenen önceliklidir)
+func (db *DB) HandleQuery(marker string, fn QueryFunc) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.handlers = append(db.handlers, handler{marker, fn})
+}
+
+// Rows - HandleQuery için sabit sonuç kısayolu
+func (db *DB) Rows(marker string, columns []string, rows ...[]driver.Value) {
+	db.HandleQuery(marker, func(string, []driver.Value) Result {
+		return Result{Columns: columns, Rows: rows}
+	})
+}
+
+// HandleExec - SQL'inde marker geçen Exec'leri fn işler (son eklenen önceliklidir, RowsAffected'dan önce gelir)
+// Sahte tabloda durum tutan testler için
+func (db *DB) HandleExec(marker string, fn ExecFunc) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.execHandlers = append(db.execHandlers, execHandler{marker, fn})
+}
+
+// RowsAffected - SQL'inde marker geçen Exec'lerin etkilediği satır sayısı (varsayılan 1)
+func (db *DB) RowsAffected(marker string, n int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rowsAffected[marker] = n
+}
+
+// Fail - SQL'inde marker geçen sorgu ve Exec'ler err ile başarısız olur
+func (db *DB) Fail(marker string, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.failures[marker] = err
+}
+
+// Statements - SQL'inde marker geçen ifadeler, çalıştırılma sırasıyla
+func (db *DB) Statements(marker string) []Statement {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var matched []Statement
+	for _, s := range db.statements {
+		if strings.Contains(s.SQL, marker) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// Commits - Commit edilen transaction sayısı
+func (db *DB) Commits() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.commits
+}
+
+// Rollbacks - Geri alınan transaction sayısı
+func (db *DB) Rollbacks() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.rollbacks
+}
+
+func (db *DB) record(query string, args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.statements = append(db.statements, Statement{SQL: query, Args: values, InTx: db.inTx})
+	return values
+}
+
+// failure - İfade için Fail ile tanımlanmış hata (kilit tutulurken çağrılır)
+func (db *DB) failure(query string) error {
+	for marker, err := range db.failures {
+		if strings.Contains(query, marker) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) query(query string, args []driver.NamedValue) (Result, error) {
+	values := db.record(query, args)
+
+	db.mu.Lock()
+	if err := db.failure(query); err != nil {
+		db.mu.Unlock()
+		return Result{}, err
+	}
+	var fn QueryFunc
+	for i := len(db.handlers) - 1; i >= 0; i-- {
+		if strings.Contains(query, db.handlers[i].marker) {
+			fn = db.handlers[i].fn
+			break
+		}
+	}
+	db.mu.Unlock()
+
+	if fn == nil {
+		return Result{}, nil
+	}
+	return fn(query, values), nil
+}
+
+func (db *DB) exec(query string, args []driver.NamedValue) (int64, error) {
+	values := db.record(query, args)
+
+	db.mu.Lock()
+	if err := db.failure(query); err != nil {
+		db.mu.Unlock()
+		return 0, err
+	}
+	var fn ExecFunc
+	for i := len(db.execHandlers) - 1; i >= 0; i-- {
+		if strings.Contains(query, db.execHandlers[i].marker) {
+			fn = db.execHandlers[i].fn
+			break
+		}
+	}
+	affected := int64(1)
+	for marker, n := range db.rowsAffected {
+		if strings.Contains(query, marker) {
+			affected = n
+			break
+		}
+	}
+	db.mu.Unlock()
+
+	if fn != nil {
+		return fn(query, values), nil
+	}
+	return affected, nil
+}
+
+type connector struct{ db *DB }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, driver.ErrSkip }
+
+type conn struct{ db *DB }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c, query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.mu.Lock()
+	c.db.inTx = true
+	c.db.mu.Unlock()
+	return tx{c.db}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	n, err := c.db.exec(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: result}, nil
+}
+
+// CheckNamedValue - Argümanlar Valuer dönüşümünden sonra olduğu gibi kaydedilir
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if valuer, ok := nv.Value.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		nv.Value = value
+	}
+	return nil
+}
+
+type tx struct{ db *DB }
+
+func (t tx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.inTx = false
+	t.db.commits++
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.inTx = false
+	t.db.rollbacks++
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+type rows struct {
+	result Result
+	next   int
+}
+
+func (r *rows) Columns() []string { return r.result.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.Rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,4 @@
+package database
+
+// NewGormLogger - database_test paketindeki testler için
+var NewGormLogger = newGormLogger
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fiber-app/pkg/tracing"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger - GORM loglarını zap'e yönlendirir, context'teki trace_id'yi ekler
+type gormLogger struct {
+	zap           *zap.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(zapLogger *zap.Logger, level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	return &gormLogger{
+		zap:           zapLogger,
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.zap.Info(fmt.Sprintf(msg, args...), traceField(ctx))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.zap.Warn(fmt.Sprintf(msg, args...), traceField(ctx))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.zap.Error(fmt.Sprintf(msg, args...), traceField(ctx))
+	}
+}
+
+// Trace - Her query sonrası çağrılır; hata, yavaş query ve (debug'da) tüm query'leri loglar
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	fields := []zap.Field{
+		traceField(ctx),
+		zap.Duration("elapsed", elapsed),
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		l.zap.Error("Database query hatası", append(fields, zap.Error(err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		l.zap.Warn("Yavaş database query",
+			append(fields, zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= logger.Info:
+		l.zap.Debug("Database query", fields...)
+	}
+}
+
+func traceField(ctx context.Context) zap.Field {
+	traceID := tracing.TraceID(ctx)
+	if traceID == "" {
+		traceID = "unknown"
+	}
+	return zap.String("trace_id", traceID)
+}
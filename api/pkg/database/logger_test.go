@@ -0,0 +1,84 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"fiber-app/internal/models"
+	"fiber-app/pkg/database"
+	"fiber-app/pkg/database/dbtest"
+	"fiber-app/pkg/tracing"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// observedDB - Query loglarını observer'a yazan, sahte veritabanına bağlı GORM session'ı
+func observedDB(t *testing.T, level logger.LogLevel, slow time.Duration) (*dbtest.DB, *gorm.DB, *observer.ObservedLogs) {
+	t.Helper()
+
+	fake := dbtest.Open(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	db := database.DB.Session(&gorm.Session{Logger: database.NewGormLogger(zap.New(core), level, slow)})
+	return fake, db, logs
+}
+
+func TestQueryLogsCarryTraceID(t *testing.T) {
+	_, db, logs := observedDB(t, logger.Info, 0)
+
+	ctx := tracing.WithTraceID(context.Background(), "trace-123")
+	db.WithContext(ctx).Find(&[]models.Role{})
+	db.Find(&[]models.Role{})
+
+	entries := logs.FilterMessage("Database query").All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d queries, want 2", len(entries))
+	}
+	if got := entries[0].ContextMap()["trace_id"]; got != "trace-123" {
+		t.Errorf("trace_id = %v, want trace-123", got)
+	}
+	if got := entries[1].ContextMap()["trace_id"]; got != "unknown" {
+		t.Errorf("trace_id without context = %v, want unknown", got)
+	}
+	if sql, _ := entries[0].ContextMap()["sql"].(string); sql == "" {
+		t.Error("sql field missing")
+	}
+}
+
+func TestSlowAndFailedQueryLogsCarryTraceID(t *testing.T) {
+	fake, db, logs := observedDB(t, logger.Warn, time.Nanosecond)
+	fake.Fail(`FROM "users"`, errors.New("connection reset"))
+
+	ctx := tracing.WithTraceID(context.Background(), "trace-456")
+	db.WithContext(ctx).Find(&[]models.Role{})
+	db.WithContext(ctx).Find(&[]models.User{})
+
+	slow := logs.FilterMessage("Yavaş database query").All()
+	if len(slow) != 1 || slow[0].ContextMap()["trace_id"] != "trace-456" {
+		t.Errorf("slow query logs = %v, want one with trace_id trace-456", slow)
+	}
+	failed := logs.FilterMessage("Database query hatası").All()
+	if len(failed) != 1 || failed[0].ContextMap()["trace_id"] != "trace-456" {
+		t.Errorf("failed query logs = %v, want one with trace_id trace-456", failed)
+	}
+	// Warn seviyesinde normal query'ler loglanmaz
+	if n := logs.FilterMessage("Database query").Len(); n != 0 {
+		t.Errorf("logged %d plain queries at warn level", n)
+	}
+}
+
+func TestRecordNotFoundIsNotLoggedAsError(t *testing.T) {
+	_, db, logs := observedDB(t, logger.Warn, 0)
+
+	var role models.Role
+	if err := db.First(&role).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("err = %v, want ErrRecordNotFound", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("logged %d entries for a not-found lookup", logs.Len())
+	}
+}
@@ -0,0 +1,30 @@
+package database
+
+// trigramSearch - pg_trgm extension'ı ve index'leri hazırsa true
+var trigramSearch bool
+
+// trigramStatements - pg_trgm extension'ı ve arama kolonlarının trigram index'leri
+var trigramStatements = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (email gin_trgm_ops)",
+}
+
+// EnableTrigramSearch - pg_trgm extension'ını ve index'leri oluşturur
+// Başarısız olursa (örn. extension yetkisi yok) arama ILIKE ile sıralamasız çalışmaya devam eder
+func EnableTrigramSearch() error {
+	for _, stmt := range trigramStatements {
+		if err := DB.Exec(stmt).Error; err != nil {
+			trigramSearch = false
+			return err
+		}
+	}
+
+	trigramSearch = true
+	return nil
+}
+
+// TrigramSearchEnabled - Aramada similarity sıralaması kullanılabilir mi
+func TrigramSearchEnabled() bool {
+	return trigramSearch
+}
@@ -0,0 +1,33 @@
+package database_test
+
+import (
+	"errors"
+	"fiber-app/pkg/database"
+	"fiber-app/pkg/database/dbtest"
+	"testing"
+)
+
+func TestEnableTrigramSearch(t *testing.T) {
+	db := dbtest.Open(t)
+
+	if err := database.EnableTrigramSearch(); err != nil {
+		t.Fatalf("EnableTrigramSearch: %v", err)
+	}
+	if !database.TrigramSearchEnabled() {
+		t.Error("trigram search should be enabled")
+	}
+	for _, marker := range []string{"CREATE EXTENSION IF NOT EXISTS pg_trgm", "idx_users_name_trgm", "idx_users_email_trgm"} {
+		if len(db.Statements(marker)) != 1 {
+			t.Errorf("%q was not executed", marker)
+		}
+	}
+
+	// Extension kurulamazsa arama sıralamasız ILIKE'a düşer
+	db.Fail("pg_trgm", errors.New("permission denied to create extension"))
+	if err := database.EnableTrigramSearch(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if database.TrigramSearchEnabled() {
+		t.Error("trigram search should be disabled after a failed setup")
+	}
+}
@@ -0,0 +1,214 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultLanguage - Accept-Language desteklenen bir dil içermiyorsa kullanılır
+const DefaultLanguage = "en"
+
+// Code - Client'ların dilden bağımsız kullanabileceği hata kodu
+type Code string
+
+const (
+	CodeInternalError Code = "internal_error"
+	CodeDatabaseError Code = "database_error"
+	CodeInvalidJSON   Code = "invalid_json"
+	CodeServerBusy    Code = "server_busy"
+	CodeRateLimited   Code = "rate_limited"
+
+	CodeUserIDRequired   Code = "user_id_required"
+	CodeInvalidUserID    Code = "invalid_user_id"
+	CodeUserNotFound     Code = "user_not_found"
+	CodeEmailTaken       Code = "email_taken"
+	CodeUserNotDeleted   Code = "user_not_deleted"
+	CodeNameRequired     Code = "name_required"
+	CodeEmailRequired    Code = "email_required"
+	CodeNoFieldsToUpdate Code = "no_fields_to_update"
+	CodeFieldTooLong     Code = "field_too_long"
+	CodeInvalidEmail     Code = "invalid_email"
+	CodeAgeOutOfRange    Code = "age_out_of_range"
+	CodeInvalidSort      Code = "invalid_sort"
+	CodeInvalidCursor    Code = "invalid_cursor"
+
+	CodeRoleIDRequired Code = "role_id_required"
+	CodeInvalidRoleID  Code = "invalid_role_id"
+	CodeRoleNotFound   Code = "role_not_found"
+	CodeUnknownRole    Code = "unknown_role"
+	CodeRoleNameTaken  Code = "role_name_taken"
+	CodeRoleInUse      Code = "role_in_use"
+
+	CodeImportEmpty         Code = "import_empty"
+	CodeImportTooLarge      Code = "import_too_large"
+	CodeInvalidImportFormat Code = "invalid_import_format"
+	CodeDuplicateImportRow  Code = "duplicate_import_row"
+
+	CodeCacheStatsFailed       Code = "cache_stats_failed"
+	CodeCacheKeysFailed        Code = "cache_keys_failed"
+	CodeCacheKeyRequired       Code = "cache_key_required"
+	CodeCacheDeleteFailed      Code = "cache_delete_failed"
+	CodeCacheFlushFailed       Code = "cache_flush_failed"
+	CodeAuthStateCleanupFailed Code = "auth_state_cleanup_failed"
+
+	CodeAuthNotConfigured         Code = "auth_not_configured"
+	CodeAuthURLFailed             Code = "auth_url_failed"
+	CodeRedirectURINotAllowed     Code = "redirect_uri_not_allowed"
+	CodeTooManyPendingLogins      Code = "too_many_pending_logins"
+	CodeStateRequired             Code = "state_required"
+	CodeInvalidState              Code = "invalid_state"
+	CodeCodeRequired              Code = "code_required"
+	CodeCallbackFailed            Code = "callback_failed"
+	CodeCallbackInProgress        Code = "callback_in_progress"
+	CodeLoginAlreadyCompleted     Code = "login_already_completed"
+	CodeTokenExchangeFailed       Code = "token_exchange_failed"
+	CodeStepUpRequired            Code = "step_up_required"
+	CodeAuthContextFailed         Code = "auth_context_failed"
+	CodeUnauthorizedParty         Code = "unauthorized_party"
+	CodeInvalidNonce              Code = "invalid_nonce"
+	CodeUserInfoFailed            Code = "user_info_failed"
+	CodeTokenCreationFailed       Code = "token_creation_failed"
+	CodeInvalidSession            Code = "invalid_session"
+	CodeAuthorizationRequired     Code = "authorization_required"
+	CodeInvalidAuthorizationValue Code = "invalid_authorization_header"
+	CodeInvalidToken              Code = "invalid_token"
+	CodeClientCertMismatch        Code = "client_cert_mismatch"
+	CodeInsufficientPermissions   Code = "insufficient_permissions"
+)
+
+// messages - Kod bazında dil -> mesaj kataloğu
+var messages = map[Code]map[string]string{
+	CodeInternalError: {"en": "Internal server error", "tr": "Sunucu hatası"},
+	CodeDatabaseError: {"en": "Database error", "tr": "Database hatası"},
+	CodeInvalidJSON:   {"en": "Invalid JSON body", "tr": "Geçersiz JSON formatı"},
+	CodeServerBusy:    {"en": "Server is busy, please try again shortly", "tr": "Sunucu meşgul, lütfen kısa süre sonra tekrar deneyin"},
+	CodeRateLimited:   {"en": "Too many requests, please try again later", "tr": "Çok fazla istek, lütfen daha sonra tekrar deneyin"},
+
+	CodeUserIDRequired:   {"en": "User ID is required", "tr": "User ID gerekli"},
+	CodeInvalidUserID:    {"en": "Invalid user ID format", "tr": "Geçersiz User ID formatı"},
+	CodeUserNotFound:     {"en": "User not found", "tr": "User bulunamadı"},
+	CodeEmailTaken:       {"en": "This email address is already in use", "tr": "Bu email adresi zaten kullanımda"},
+	CodeUserNotDeleted:   {"en": "User is not deleted", "tr": "User silinmemiş"},
+	CodeNameRequired:     {"en": "Name is required", "tr": "Name alanı gerekli"},
+	CodeEmailRequired:    {"en": "Email is required", "tr": "Email alanı gerekli"},
+	CodeNoFieldsToUpdate: {"en": "No fields to update", "tr": "Güncellenecek alan bulunamadı"},
+	CodeFieldTooLong:     {"en": "%s must be at most %d characters", "tr": "%s en fazla %d karakter olabilir"},
+	CodeInvalidEmail:     {"en": "Invalid email format", "tr": "Geçersiz email formatı"},
+	CodeAgeOutOfRange:    {"en": "Age must be between %d and %d", "tr": "Age %d-%d arasında olmalı"},
+	CodeInvalidSort:      {"en": "Invalid sort field or order", "tr": "Geçersiz sıralama alanı veya yönü"},
+	CodeInvalidCursor:    {"en": "Invalid pagination cursor", "tr": "Geçersiz sayfalama cursor değeri"},
+
+	CodeRoleIDRequired: {"en": "Role ID is required", "tr": "Role ID gerekli"},
+	CodeInvalidRoleID:  {"en": "Invalid role ID format", "tr": "Geçersiz Role ID formatı"},
+	CodeRoleNotFound:   {"en": "Role not found", "tr": "Role bulunamadı"},
+	CodeUnknownRole:    {"en": "Role does not exist", "tr": "Geçersiz role ID"},
+	CodeRoleNameTaken:  {"en": "This role name is already in use", "tr": "Bu role adı zaten kullanımda"},
+	CodeRoleInUse:      {"en": "Role is assigned to users and cannot be deleted", "tr": "Bu role'ü kullanan kullanıcılar var, silinemez"},
+
+	CodeImportEmpty:         {"en": "No records to import", "tr": "Import edilecek kayıt bulunamadı"},
+	CodeImportTooLarge:      {"en": "At most %d rows can be imported", "tr": "En fazla %d satır import edilebilir"},
+	CodeInvalidImportFormat: {"en": "Invalid import format", "tr": "Geçersiz import formatı"},
+	CodeDuplicateImportRow:  {"en": "Email is repeated from row %d", "tr": "Email %d. satırda tekrar ediyor"},
+
+	CodeCacheStatsFailed:       {"en": "Could not get cache stats", "tr": "Cache stats alınamadı"},
+	CodeCacheKeysFailed:        {"en": "Could not list cache keys", "tr": "Cache keys alınamadı"},
+	CodeCacheKeyRequired:       {"en": "Key parameter is required", "tr": "Key parametresi gerekli"},
+	CodeCacheDeleteFailed:      {"en": "Could not delete cache key", "tr": "Cache key silinemedi"},
+	CodeCacheFlushFailed:       {"en": "Cache flush failed", "tr": "Cache flush başarısız"},
+	CodeAuthStateCleanupFailed: {"en": "Auth state cleanup failed", "tr": "Auth state cleanup başarısız"},
+
+	CodeAuthNotConfigured:         {"en": "Auth service is not configured", "tr": "Auth service yapılandırılmamış"},
+	CodeAuthURLFailed:             {"en": "Could not create auth URL", "tr": "Auth URL oluşturulamadı"},
+	CodeRedirectURINotAllowed:     {"en": "Redirect URI is not allowed", "tr": "Redirect URI izinli değil"},
+	CodeTooManyPendingLogins:      {"en": "Too many pending login attempts, please try again later", "tr": "Çok fazla bekleyen giriş denemesi, lütfen daha sonra tekrar deneyin"},
+	CodeStateRequired:             {"en": "State parameter is required", "tr": "State parameter gerekli"},
+	CodeInvalidState:              {"en": "Invalid state parameter", "tr": "Geçersiz state parameter"},
+	CodeCodeRequired:              {"en": "Authorization code is required", "tr": "Authorization code gerekli"},
+	CodeCallbackFailed:            {"en": "Callback could not be processed", "tr": "Callback işlenemedi"},
+	CodeCallbackInProgress:        {"en": "This login is already being processed or has failed", "tr": "Bu giriş işlemi zaten işleniyor veya başarısız oldu"},
+	CodeLoginAlreadyCompleted:     {"en": "This login has already been completed", "tr": "Bu giriş işlemi zaten tamamlandı"},
+	CodeTokenExchangeFailed:       {"en": "Token exchange failed", "tr": "Token exchange başarısız"},
+	CodeStepUpRequired:            {"en": "Stronger authentication is required", "tr": "Daha güçlü kimlik doğrulama gerekli"},
+	CodeAuthContextFailed:         {"en": "Authentication context could not be verified", "tr": "Authentication context doğrulanamadı"},
+	CodeUnauthorizedParty:         {"en": "Token was not issued to this client", "tr": "Token bu istemci için verilmemiş"},
+	CodeInvalidNonce:              {"en": "ID token nonce does not match the login request", "tr": "ID token nonce değeri login isteğiyle eşleşmiyor"},
+	CodeUserInfoFailed:            {"en": "Could not get user info", "tr": "User info alınamadı"},
+	CodeTokenCreationFailed:       {"en": "Could not create JWT token", "tr": "JWT token oluşturulamadı"},
+	CodeInvalidSession:            {"en": "Invalid session", "tr": "Geçersiz oturum"},
+	CodeAuthorizationRequired:     {"en": "Authorization header is required", "tr": "Authorization header gerekli"},
+	CodeInvalidAuthorizationValue: {"en": "Invalid authorization header format", "tr": "Geçersiz authorization header formatı"},
+	CodeInvalidToken:              {"en": "Invalid token", "tr": "Geçersiz token"},
+	CodeClientCertMismatch:        {"en": "Client certificate does not match the token", "tr": "İstemci sertifikası token ile eşleşmiyor"},
+	CodeInsufficientPermissions:   {"en": "Insufficient permissions", "tr": "Yetersiz yetki"},
+}
+
+// Message - Kodun verilen dildeki mesajı (dil yoksa default dil, kod yoksa kodun kendisi)
+func Message(lang string, code Code, args ...interface{}) string {
+	translations, ok := messages[code]
+	if !ok {
+		return string(code)
+	}
+
+	message, ok := translations[lang]
+	if !ok {
+		message = translations[DefaultLanguage]
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// T - İsteğin Accept-Language header'ına göre mesaj
+func T(c *fiber.Ctx, code Code, args ...interface{}) string {
+	return Message(Language(c.Get(fiber.HeaderAcceptLanguage)), code, args...)
+}
+
+// Language - Accept-Language header'ından desteklenen en yüksek öncelikli dili seçer
+func Language(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		// tr-TR -> tr
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		candidates = append(candidates, candidate{lang: primary, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, cand := range candidates {
+		if cand.q > 0 && supported(cand.lang) {
+			return cand.lang
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// supported - Katalogda bu dilde mesaj var mı
+func supported(lang string) bool {
+	return lang == "en" || lang == "tr"
+}
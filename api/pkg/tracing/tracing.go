@@ -0,0 +1,21 @@
+package tracing
+
+import "context"
+
+type traceIDKey struct{}
+
+// WithTraceID - Context'e request trace_id'sini ekler
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID - Context'teki trace_id'yi döner, yoksa boş string
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return traceID
+	}
+	return ""
+}
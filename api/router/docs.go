@@ -0,0 +1,212 @@
+package router
+
+import (
+	_ "fiber-app/docs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/swagger"
+)
+
+// setupDocsRoutes - Swagger UI, swagger.json ve /docs sayfası
+func setupDocsRoutes(app *fiber.App) {
+	// Swagger documentation (UI asset'leri binary'ye gömülü)
+	app.Get("/swagger/*", swagger.HandlerDefault)
+
+	// Swagger JSON endpoint
+	app.Get("/swagger.json", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"swagger": "2.0",
+			"info": fiber.Map{
+				"title":       "Fiber App API",
+				"description": "Go Fiber app with PostgreSQL, GORM, Zap Logger, Trace ID and Role-based User Management",
+				"version":     "1.0.0",
+			},
+			"host":     "localhost:3003",
+			"basePath": "/",
+			"schemes":  []string{"http"},
+			"paths": fiber.Map{
+				"/": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Ana sayfa",
+						"description": "Uygulama ana sayfası ve endpoint listesi",
+						"tags":        []string{"General"},
+						"responses": fiber.Map{
+							"200": fiber.Map{
+								"description": "Başarılı",
+							},
+						},
+					},
+				},
+				"/api/v1/users": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Kullanıcıları listele",
+						"description": "Sayfalama ve arama desteği ile kullanıcıları listele",
+						"tags":        []string{"Users"},
+						"parameters": []fiber.Map{
+							{
+								"name":        "page",
+								"in":          "query",
+								"description": "Sayfa numarası",
+								"type":        "integer",
+								"default":     1,
+							},
+							{
+								"name":        "limit",
+								"in":          "query",
+								"description": "Sayfa başına kayıt sayısı",
+								"type":        "integer",
+								"default":     10,
+							},
+							{
+								"name":        "search",
+								"in":          "query",
+								"description": "Arama terimi",
+								"type":        "string",
+							},
+						},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+							"500": fiber.Map{"description": "Sunucu hatası"},
+						},
+					},
+					"post": fiber.Map{
+						"summary":     "Yeni kullanıcı oluştur",
+						"description": "Yeni kullanıcı kaydı oluştur",
+						"tags":        []string{"Users"},
+						"responses": fiber.Map{
+							"201": fiber.Map{"description": "Oluşturuldu"},
+							"400": fiber.Map{"description": "Geçersiz istek"},
+							"409": fiber.Map{"description": "Çakışma"},
+							"500": fiber.Map{"description": "Sunucu hatası"},
+						},
+					},
+				},
+				"/api/v1/users/{id}": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Kullanıcı detayı",
+						"description": "ID ile kullanıcı detayını getir",
+						"tags":        []string{"Users"},
+						"parameters": []fiber.Map{
+							{
+								"name":        "id",
+								"in":          "path",
+								"description": "User ID (UUID)",
+								"required":    true,
+								"type":        "string",
+							},
+						},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+							"404": fiber.Map{"description": "Bulunamadı"},
+							"500": fiber.Map{"description": "Sunucu hatası"},
+						},
+					},
+					"put": fiber.Map{
+						"summary":     "Kullanıcı güncelle",
+						"description": "Mevcut kullanıcı bilgilerini güncelle",
+						"tags":        []string{"Users"},
+						"parameters": []fiber.Map{
+							{
+								"name":        "id",
+								"in":          "path",
+								"description": "User ID (UUID)",
+								"required":    true,
+								"type":        "string",
+							},
+						},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+							"404": fiber.Map{"description": "Bulunamadı"},
+							"500": fiber.Map{"description": "Sunucu hatası"},
+						},
+					},
+					"delete": fiber.Map{
+						"summary":     "Kullanıcı sil",
+						"description": "Kullanıcıyı sistemden sil",
+						"tags":        []string{"Users"},
+						"parameters": []fiber.Map{
+							{
+								"name":        "id",
+								"in":          "path",
+								"description": "User ID (UUID)",
+								"required":    true,
+								"type":        "string",
+							},
+						},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+							"404": fiber.Map{"description": "Bulunamadı"},
+							"500": fiber.Map{"description": "Sunucu hatası"},
+						},
+					},
+				},
+				"/api/v1/roles": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Rolleri listele",
+						"description": "Sayfalama desteği ile rolleri listele",
+						"tags":        []string{"Roles"},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+						},
+					},
+					"post": fiber.Map{
+						"summary":     "Yeni rol oluştur",
+						"description": "Yeni rol kaydı oluştur",
+						"tags":        []string{"Roles"},
+						"responses": fiber.Map{
+							"201": fiber.Map{"description": "Oluşturuldu"},
+						},
+					},
+				},
+				"/api/v1/health": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Sağlık kontrolü",
+						"description": "Uygulamanın genel sağlık durumu",
+						"tags":        []string{"Health"},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Sağlıklı"},
+						},
+					},
+				},
+				"/api/v1/metrics": fiber.Map{
+					"get": fiber.Map{
+						"summary":     "Uygulama metrikleri",
+						"description": "Temel uygulama performans metrikleri",
+						"tags":        []string{"Metrics"},
+						"responses": fiber.Map{
+							"200": fiber.Map{"description": "Başarılı"},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	// Simple Swagger UI endpoint (UI asset'leri CDN yerine /swagger altından yerel servis edilir)
+	app.Get("/docs", func(c *fiber.Ctx) error {
+		html := `<!DOCTYPE html>
+<html>
+<head>
+    <title>API Documentation</title>
+    <link rel="stylesheet" type="text/css" href="/swagger/swagger-ui.css" />
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="/swagger/swagger-ui-bundle.js"></script>
+    <script src="/swagger/swagger-ui-standalone-preset.js"></script>
+    <script>
+        SwaggerUIBundle({
+            url: '/swagger.json',
+            dom_id: '#swagger-ui',
+            presets: [
+                SwaggerUIBundle.presets.apis,
+                SwaggerUIStandalonePreset
+            ]
+        });
+    </script>
+</body>
+</html>`
+		c.Set("Content-Type", "text/html")
+		return c.SendString(html)
+	})
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"fiber-app/pkg/config"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var docsPaths = []string{"/docs", "/swagger.json", "/swagger/index.html", "/swagger/swagger-ui.css", "/swagger/swagger-ui-bundle.js"}
+
+func TestDocsRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{"enabled", true, fiber.StatusOK},
+		{"disabled", false, fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			SetupRoutes(app, &config.Config{DocsEnabled: tt.enabled})
+
+			for _, path := range docsPaths {
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+				if err != nil {
+					t.Fatalf("GET %s: %v", path, err)
+				}
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestDocsPageLoadsLocalAssets(t *testing.T) {
+	app := fiber.New()
+	SetupRoutes(app, &config.Config{DocsEnabled: true})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/docs", nil))
+	if err != nil {
+		t.Fatalf("GET /docs: %v", err)
+	}
+	html, _ := io.ReadAll(resp.Body)
+
+	// Tüm script ve stylesheet'ler aynı origin'den gelmeli (CDN yok)
+	assets := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(string(html), -1)
+	if len(assets) == 0 {
+		t.Fatal("docs page references no assets")
+	}
+	for _, asset := range assets {
+		if !strings.HasPrefix(asset[1], "/") || strings.HasPrefix(asset[1], "//") {
+			t.Errorf("asset %q is not served locally", asset[1])
+		}
+	}
+}